@@ -1,9 +1,14 @@
 package main
 
+import "sync"
+
 // MetricDestination is the interface which defines a method to send
 // PublishMetrics to a certain destination.
 type MetricDestination interface {
 	Send(pm PublishMetric)
+	// Flush delivers the PublishMetrics the destination still holds, it is
+	// called when the monitor shuts down.
+	Flush()
 }
 
 // Aggregator reads PublishMetrics from a channel and distributes them to
@@ -11,21 +16,35 @@ type MetricDestination interface {
 type Aggregator struct {
 	publishMetricSource       chan PublishMetric
 	publishMetricDestinations []MetricDestination
+	sends                     *sync.WaitGroup
 }
 
 // NewAggregator returns an Aggregator which reads messages from inputChannel and
 // distributes them to destinations.
 func NewAggregator(inputChannel chan PublishMetric, destinations []MetricDestination) *Aggregator {
-	return &Aggregator{inputChannel, destinations}
+	return &Aggregator{inputChannel, destinations, &sync.WaitGroup{}}
 }
 
 // Run reads PublishMetrics from a channel and distributes them to a list of
 // MetricDestinations.
-// Stops reading when the channel is closed.
+// Stops reading when the channel is closed, and returns once all the
+// PublishMetrics read were sent.
 func (a *Aggregator) Run() {
 	for publishMetric := range a.publishMetricSource {
 		for _, sender := range a.publishMetricDestinations {
-			go sender.Send(publishMetric)
+			a.sends.Add(1)
+			go func(sender MetricDestination, pm PublishMetric) {
+				defer a.sends.Done()
+				sender.Send(pm)
+			}(sender, publishMetric)
 		}
 	}
+	a.sends.Wait()
+}
+
+// Flush flushes all the MetricDestinations.
+func (a *Aggregator) Flush() {
+	for _, destination := range a.publishMetricDestinations {
+		destination.Flush()
+	}
 }
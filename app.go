@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -17,11 +18,14 @@ import (
 
 	"github.com/Financial-Times/message-queue-gonsumer/consumer"
 	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/Financial-Times/publish-availability-monitor/content"
 	"github.com/Financial-Times/publish-availability-monitor/feeds"
 	"github.com/Financial-Times/publish-availability-monitor/logformat"
+	"github.com/Financial-Times/publish-availability-monitor/tracing"
 	status "github.com/Financial-Times/service-status-go/httphandlers"
 	log "github.com/Sirupsen/logrus"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // Interval is a simple representation of an interval of time, with a lower and
@@ -42,16 +46,31 @@ type PublishMetric struct {
 	endpoint        url.URL
 	tid             string
 	isMarkedDeleted bool
+	contentType     string
+	outcome         string            //set when the content arrived late or the check could not be completed, see outcomeOf
+	threshold       int               //the SLA in seconds
+	latency         time.Duration     //how long after the publish the content was available, set when it was found
+	findings        []string          //quality issues of the content found, ex. duplicate notifications
+	findingsReport  bool              //only reports the findings of a check whose result was sent before, see watchFindings
+	expectedFields  map[string]string //fields of the published content verified at the endpoint, see MetricConfig.VerifyFields
 }
 
 // MetricConfig is the configuration of a PublishMetric
 type MetricConfig struct {
-	Granularity  int      `json:"granularity"` //how we split up the threshold, ex. 120/12
-	Endpoint     string   `json:"endpoint"`
-	ContentTypes []string `json:"contentTypes"` //list of valid eom types for this metric
-	Alias        string   `json:"alias"`
-	Health       string   `json:"health,omitempty"`
-	ApiKey       string   `json:"apiKey,omitempty"`
+	Granularity             int                   `json:"granularity"`         //how we split up the threshold, ex. 120/12
+	Threshold               int                   `json:"threshold,omitempty"` //pub SLA in seconds at this endpoint, overrides the content type and global thresholds
+	Endpoint                string                `json:"endpoint"`
+	ContentTypes            []string              `json:"contentTypes"` //list of valid eom types for this metric
+	Alias                   string                `json:"alias"`
+	Health                  string                `json:"health,omitempty"`
+	ApiKey                  string                `json:"apiKey,omitempty"`
+	HeartbeatTimeout        int                   `json:"heartbeatTimeout,omitempty"`        //seconds without heartbeat after which a push feed is unhealthy, ex. 90
+	FeedType                string                `json:"feedType,omitempty"`                //registered type of the notifications feed at this endpoint, ex. "Notifications-Pull"
+	MaxBufferedUUIDs        int                   `json:"maxBufferedUuids,omitempty"`        //UUIDs buffered by the feed before the least recently notified are evicted, ex. 50000
+	MaxNotificationsPerUUID int                   `json:"maxNotificationsPerUuid,omitempty"` //notifications buffered for each UUID, the oldest are evicted first, ex. 20
+	QueueConf               *consumer.QueueConfig `json:"queueConfig,omitempty"`             //topic and consumer group of a Kafka feed, the proxy address defaults to the endpoint
+	VerifyFields            []string              `json:"verifyFields,omitempty"`            //fields of the published content compared with the content served at a content endpoint, ex. ["title", "byline"]
+	Check                   *GenericCheckConfig   `json:"check,omitempty"`                   //checks the endpoint without an endpoint specific check, see GenericCheck
 }
 
 // SplunkConfig holds the SplunkFeeder-specific configuration
@@ -61,13 +80,24 @@ type SplunkConfig struct {
 
 // AppConfig holds the application's configuration
 type AppConfig struct {
-	Threshold           int                  `json:"threshold"` //pub SLA in seconds, ex. 120
-	QueueConf           consumer.QueueConfig `json:"queueConfig"`
-	MetricConf          []MetricConfig       `json:"metricConfig"`
-	SplunkConf          SplunkConfig         `json:"splunk-config"`
-	HealthConf          HealthConfig         `json:"healthConfig"`
-	ValidationEndpoints map[string]string    `json:"validationEndpoints"` //contentType to validation endpoint mapping, ex. { "EOM::Story": "http://methode-article-transformer/content-transform" }
-	UUIDResolverUrl     string               `json:"uuidResolverUrl"`
+	Threshold             int                             `json:"threshold"`                       //pub SLA in seconds, ex. 120
+	ContentTypeThresholds map[string]int                  `json:"contentTypeThresholds,omitempty"` //pub SLA in seconds per content type, overrides the global threshold, ex. { "Image": 60 }
+	GraceWindow           int                             `json:"graceWindow"`                     //how long checks continue after the SLA to detect late publishes, in seconds, ex. 180
+	QueueConf             consumer.QueueConfig            `json:"queueConfig"`
+	MetricConf            []MetricConfig                  `json:"metricConfig"`
+	SplunkConf            SplunkConfig                    `json:"splunk-config"`
+	HealthConf            HealthConfig                    `json:"healthConfig"`
+	ValidationEndpoints   map[string]string               `json:"validationEndpoints"`      //contentType to validation endpoint mapping, ex. { "EOM::Story": "http://methode-article-transformer/content-transform" }
+	ContentSources        map[string]content.SourceConfig `json:"contentSources,omitempty"` //CMSs without a content implementation of their own, by system ID
+	TypeRules             []TypeRule                      `json:"typeRules,omitempty"`      //derive the type content is monitored as from its message, the first matching rule applies
+	UUIDResolverUrl       string                          `json:"uuidResolverUrl"`
+	HistoryConf           HistoryConfig                   `json:"historyConfig"`
+	DrainDeadline         int                             `json:"drainDeadline"` //how long running checks can complete on shutdown, in seconds, ex. 20
+	RecheckConf           RecheckConfig                   `json:"recheckConfig"`
+	CheckpointConf        CheckpointConfig                `json:"checkpointConfig"`
+	AlertConf             AlertConfig                     `json:"alertConfig"`
+	SyntheticConf         SyntheticConfig                 `json:"syntheticConfig"`
+	ComparisonConf        ComparisonConfig                `json:"comparisonConfig"`
 }
 
 // HealthConfig holds the application's healthchecks configuration
@@ -90,11 +120,6 @@ type Credentials struct {
 	Password string `json:"password"`
 }
 
-type publishHistory struct {
-	sync.RWMutex
-	publishMetrics []PublishMetric
-}
-
 const dateLayout = time.RFC3339Nano
 
 var configFileName = flag.String("config", "", "Path to configuration file")
@@ -109,24 +134,51 @@ var envsFileName = flag.String("envs-file-name", "/etc/pam/envs/read-environment
 var envCredentialsFileName = flag.String("envs-credentials-file-name", "/etc/pam/credentials/read-environments-credentials.json", "Path to json file that contains environments credentials")
 var validatorCredentialsFileName = flag.String("validator-credentials-file-name", "/etc/pam/credentials/validator-credentials.json", "Path to json file that contains validation endpoints configuration")
 var configRefreshPeriod = flag.Int("config-refresh-period", 1, "Refresh period for configuration in minutes. By default it is 1 minute.")
+var otlpEndpoint = flag.String("otlp-endpoint", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "OTLP/HTTP endpoint of the collector the traces of the publishes are exported to, ex. http://localhost:4318. The publishes are not traced by default.")
+var traceSamplingRatio = flag.Float64("trace-sampling-ratio", 1, "Ratio of the publishes traced, between 0 and 1, when the publish is not already traced by the CMS.")
+var logFormat = flag.String("log-format", "text", "Format of the logs: text, in the SLF4J format, or json, with the fields of each entry as JSON properties")
 
 var appConfig *AppConfig
 var environments = newThreadSafeEnvironments()
 var subscribedFeeds = make(map[string][]feeds.Feed)
+var subscribedFeedsLock = &sync.RWMutex{} //guards subscribedFeeds, which is updated when the environments change
 var metricSink = make(chan PublishMetric)
-var metricContainer publishHistory
+var metricContainer PublishHistory
+var checkpoints CheckpointStore = newMemoryCheckpoints()
+var syntheticPublishes *syntheticPublisher
+var environmentComparator *EnvironmentComparator
 var validatorCredentials string
 var configFilesHashValues = make(map[string]string)
 var carouselTransactionIDRegExp = regexp.MustCompile(`^.+_carousel_[\d]{10}.*$`)
 
+const codeLocationPattern = `.*/github\.com/Financial-Times/.*`
+
 func init() {
-	f := logformat.NewSLF4JFormatter(`.*/github\.com/Financial-Times/.*`)
+	f := logformat.NewSLF4JFormatter(codeLocationPattern)
 	log.SetFormatter(f)
 }
 
 func main() {
 	flag.Parse()
 
+	switch *logFormat {
+	case "text":
+	case "json":
+		log.SetFormatter(logformat.NewJSONFormatter(codeLocationPattern))
+	default:
+		log.Errorf("Unknown log format [%v], the logs are formatted as text", *logFormat)
+	}
+
+	if *otlpEndpoint != "" {
+		log.Infof("Exporting the traces of the publishes to [%v]", *otlpEndpoint)
+		shutdownTracing, err := tracing.Enable(*otlpEndpoint, *traceSamplingRatio)
+		if err != nil {
+			log.WithError(err).Error("Cannot export the traces of the publishes")
+		} else {
+			defer shutdownTracing(context.Background())
+		}
+	}
+
 	brandMappings := readBrandMappings()
 
 	var err error
@@ -135,6 +187,15 @@ func main() {
 		log.WithError(err).Error("Cannot load configuration")
 		return
 	}
+	registerFeedChecks(appConfig.MetricConf)
+	registerGenericChecks(appConfig.MetricConf)
+	registerContentSources(appConfig.ContentSources)
+	if len(appConfig.TypeRules) > 0 {
+		if contentTypeRouter, err = newTypeRouter(appConfig.TypeRules); err != nil {
+			log.WithError(err).Error("Invalid type rules")
+			return
+		}
+	}
 
 	wg := new(sync.WaitGroup)
 	wg.Add(1)
@@ -147,18 +208,43 @@ func main() {
 	}
 	wg.Wait()
 
-	metricContainer = publishHistory{sync.RWMutex{}, make([]PublishMetric, 0)}
+	metricContainer, err = NewPublishHistory(appConfig.HistoryConf)
+	if err != nil {
+		log.WithError(err).Error("Cannot open publish history")
+		return
+	}
+	defer metricContainer.Close()
+
+	checkpoints, err = NewCheckpointStore(appConfig.CheckpointConf)
+	if err != nil {
+		log.WithError(err).Error("Cannot open checkpoints")
+		return
+	}
+	defer checkpoints.Close()
+
+	syntheticPublishes = newSyntheticPublisher(appConfig.SyntheticConf, &http.Client{Timeout: 10 * time.Second}, environments)
 
-	go startHttpListener()
+	environmentComparator = NewEnvironmentComparator(appConfig.ComparisonConf, appConfig.GraceWindow, environments)
 
-	startAggregator()
+	server := startHttpListener()
+
+	aggregator, aggregatorDone := startAggregator()
 	readMessages(brandMappings)
+	shutdown(server, aggregator, aggregatorDone)
 }
 
-func startHttpListener() {
+func startHttpListener() *http.Server {
 	router := mux.NewRouter()
 	setupHealthchecks(router)
-	router.HandleFunc("/__history", loadHistory)
+	router.HandleFunc("/__history", historyHandler(metricContainer))
+	router.HandleFunc("/__feeds", feedsHandler(subscribedFeedsSnapshot))
+	router.Handle("/metrics", promhttp.Handler())
+	router.HandleFunc("/__environment-comparison", environmentComparisonHandler(environmentComparator))
+	if appConfig.RecheckConf.APIKey != "" {
+		router.HandleFunc("/__recheck", recheckHandler(appConfig.RecheckConf.APIKey, metricContainer, environments)).Methods("POST")
+	} else {
+		log.Info("No API key configured for rechecks, /__recheck is disabled")
+	}
 
 	router.HandleFunc(status.PingPath, status.PingHandler)
 	router.HandleFunc(status.PingPathDW, status.PingHandler)
@@ -169,14 +255,19 @@ func startHttpListener() {
 	attachProfiler(router)
 
 	http.Handle("/", router)
-	err := http.ListenAndServe(":8080", nil)
-	if err != nil {
-		log.Panicf("Couldn't set up HTTP listener: %+v\n", err)
-	}
+	server := &http.Server{Addr: ":8080"}
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			log.Panicf("Couldn't set up HTTP listener: %+v\n", err)
+		}
+	}()
+	return server
 }
 
 func setupHealthchecks(router *mux.Router) {
-	hc := newHealthcheck(appConfig, &metricContainer)
+	hc := newHealthcheck(appConfig, metricContainer)
+	hc.synthetic = syntheticPublishes
 	router.HandleFunc("/__health", hc.checkHealth())
 	router.HandleFunc(status.GTGPath, status.NewGoodToGoHandler(hc.GTG))
 }
@@ -195,6 +286,11 @@ func readMessages(brandMappings map[string]string) {
 		time.Sleep(3 * time.Second)
 	}
 
+	resumeChecks(checkpoints, metricContainer, environments)
+	if syntheticPublishes != nil {
+		syntheticPublishes.Start()
+	}
+
 	var typeRes typeResolver
 	for _, envName := range environments.names() {
 		env := environments.environment(envName)
@@ -219,25 +315,30 @@ func readMessages(brandMappings map[string]string) {
 	ch := make(chan os.Signal)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
 	<-ch
+	log.Info("Stopping consuming messages")
 	c.Stop()
 	wg.Wait()
 }
 
-func startAggregator() {
+// startAggregator runs the Aggregator until metricSink is closed; the returned
+// channel is closed once all the metrics were sent.
+func startAggregator() (*Aggregator, <-chan struct{}) {
 	var destinations []MetricDestination
 
 	splunkFeeder := NewSplunkFeeder(appConfig.SplunkConf.LogPrefix)
 	destinations = append(destinations, splunkFeeder)
-	aggregator := NewAggregator(metricSink, destinations)
-	go aggregator.Run()
-}
-
-func loadHistory(w http.ResponseWriter, r *http.Request) {
-	metricContainer.RLock()
-	for i := len(metricContainer.publishMetrics) - 1; i >= 0; i-- {
-		fmt.Fprintf(w, "%d. %v\n\n", len(metricContainer.publishMetrics)-i, metricContainer.publishMetrics[i])
+	destinations = append(destinations, NewPrometheusFeeder())
+	destinations = append(destinations, environmentComparator)
+	if webhookFeeder := NewWebhookFeeder(appConfig.AlertConf, &http.Client{Timeout: 10 * time.Second}); webhookFeeder != nil {
+		destinations = append(destinations, webhookFeeder)
 	}
-	metricContainer.RUnlock()
+	aggregator := NewAggregator(metricSink, destinations)
+	done := make(chan struct{})
+	go func() {
+		aggregator.Run()
+		close(done)
+	}()
+	return aggregator, done
 }
 
 func readBrandMappings() map[string]string {
@@ -255,6 +356,19 @@ func readBrandMappings() map[string]string {
 	return brandMappings
 }
 
+// subscribedFeedsSnapshot returns a copy of subscribedFeeds, which can be
+// read while the feeds of the environments are updated.
+func subscribedFeedsSnapshot() map[string][]feeds.Feed {
+	subscribedFeedsLock.RLock()
+	defer subscribedFeedsLock.RUnlock()
+
+	snapshot := make(map[string][]feeds.Feed, len(subscribedFeeds))
+	for envName, envFeeds := range subscribedFeeds {
+		snapshot[envName] = envFeeds
+	}
+	return snapshot
+}
+
 func (pm PublishMetric) String() string {
 	return fmt.Sprintf("Tid: %s, UUID: %s, Platform: %s, Endpoint: %s, PublishDate: %s, Duration: %d, Succeeded: %t.",
 		pm.tid,
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/tracing"
+	log "github.com/Sirupsen/logrus"
+)
+
+// CheckpointStore keeps the checks which are still running, so that they can
+// be resumed when the monitor restarts.
+type CheckpointStore interface {
+	// Save stores cp and returns the ID assigned to it.
+	Save(cp checkpoint) (uint64, error)
+	Remove(id uint64) error
+	// Pending returns the stored checkpoints, the oldest first.
+	Pending() []checkpoint
+	// Durable tells whether the checkpoints outlive the process, so the checks are resumed on restart.
+	Durable() bool
+	Close() error
+}
+
+// CheckpointConfig holds the checkpoint store configuration
+type CheckpointConfig struct {
+	Store string `json:"store"` //"file" (default) or "memory"
+	Path  string `json:"path"`
+}
+
+// checkpoint is the stored representation of a PublishCheck.
+// The number of the next check is derived from the publish date and the check
+// interval when the check is resumed, as it is when it is first scheduled.
+type checkpoint struct {
+	ID              uint64            `json:"id"`
+	UUID            string            `json:"uuid,omitempty"`
+	TransactionID   string            `json:"transactionId,omitempty"`
+	Environment     string            `json:"environment,omitempty"`
+	Alias           string            `json:"alias,omitempty"`
+	Endpoint        string            `json:"endpoint,omitempty"`
+	ContentType     string            `json:"contentType,omitempty"`
+	PublishDate     time.Time         `json:"publishDate"`
+	IsMarkedDeleted bool              `json:"isMarkedDeleted,omitempty"`
+	ExpectedFields  map[string]string `json:"expectedFields,omitempty"`
+	Threshold       int               `json:"threshold,omitempty"`
+	GraceWindow     int               `json:"graceWindow,omitempty"`
+	CheckInterval   int               `json:"checkInterval,omitempty"`
+	TraceParent     string            `json:"traceParent,omitempty"` //the span of the publish, so that the resumed check stays in its trace
+	Done            bool              `json:"done,omitempty"`        //marks the removal of the checkpoint with ID in a checkpoint file
+}
+
+// NewCheckpointStore returns the CheckpointStore described by conf.
+func NewCheckpointStore(conf CheckpointConfig) (CheckpointStore, error) {
+	switch conf.Store {
+	case "memory":
+		return newMemoryCheckpoints(), nil
+	case "", "file":
+		return newFileCheckpoints(conf.Path)
+	default:
+		return nil, fmt.Errorf("unsupported checkpoint store [%s]", conf.Store)
+	}
+}
+
+func newCheckpoint(check PublishCheck) checkpoint {
+	pm := check.Metric
+	return checkpoint{
+		UUID:            pm.UUID,
+		TransactionID:   pm.tid,
+		Environment:     pm.platform,
+		Alias:           pm.config.Alias,
+		Endpoint:        pm.endpoint.String(),
+		ContentType:     pm.contentType,
+		PublishDate:     pm.publishDate,
+		IsMarkedDeleted: pm.isMarkedDeleted,
+		ExpectedFields:  pm.expectedFields,
+		Threshold:       check.Threshold,
+		GraceWindow:     check.GraceWindow,
+		CheckInterval:   check.CheckInterval,
+		TraceParent:     tracing.TraceParent(check.spanContext),
+	}
+}
+
+func (cp checkpoint) metric(config MetricConfig) PublishMetric {
+	endpoint, err := url.Parse(cp.Endpoint)
+	if err != nil {
+		endpoint = &url.URL{}
+	}
+
+	return PublishMetric{
+		UUID:            cp.UUID,
+		publishDate:     cp.PublishDate,
+		platform:        cp.Environment,
+		config:          config,
+		endpoint:        *endpoint,
+		tid:             cp.TransactionID,
+		isMarkedDeleted: cp.IsMarkedDeleted,
+		contentType:     cp.ContentType,
+		threshold:       cp.Threshold,
+		expectedFields:  cp.ExpectedFields,
+	}
+}
+
+// saveCheckpoint stores check in checkpoints, so it will be resumed if the
+// monitor restarts before it completes.
+func saveCheckpoint(check *PublishCheck) {
+	id, err := checkpoints.Save(newCheckpoint(*check))
+	if err != nil {
+		check.logger().Errorf("Cannot save checkpoint: [%v]", err)
+		return
+	}
+	check.checkpointID = id
+}
+
+func removeCheckpoint(check PublishCheck) {
+	if check.checkpointID == 0 {
+		return
+	}
+	if err := checkpoints.Remove(check.checkpointID); err != nil {
+		check.logger().Errorf("Cannot remove checkpoint: [%v]", err)
+	}
+}
+
+// resumeChecks resumes the checks which were running when the monitor stopped.
+// The checks whose SLA and grace window expired in the meantime, or which cannot be resumed
+// because their endpoint or environment is no longer configured, are recorded
+// with the "monitor-restarted" outcome.
+func resumeChecks(store CheckpointStore, metricContainer PublishHistory, envs *threadSafeEnvironments) {
+	pending := store.Pending()
+	if len(pending) == 0 {
+		return
+	}
+
+	resumed := 0
+	for _, cp := range pending {
+		config, configFound := metricConfigFor(cp.Alias)
+		env, envFound := envs.lookup(cp.Environment)
+		pm := cp.metric(config)
+		checksEnd := cp.PublishDate.Add(time.Duration(cp.Threshold+cp.GraceWindow) * time.Second)
+
+		if !configFound || !envFound || !time.Now().Before(checksEnd) || cp.CheckInterval <= 0 {
+			loggerForCheck(cp.Alias, cp.UUID, cp.Environment, cp.TransactionID).Info("Cannot resume check, recording it as interrupted by the restart")
+			pm.outcome = outcomeRestarted
+			metricSink <- pm
+			updateHistory(metricContainer, pm)
+			if err := store.Remove(cp.ID); err != nil {
+				log.Errorf("Cannot remove checkpoint [%d]: [%v]", cp.ID, err)
+			}
+			continue
+		}
+
+		check := NewPublishCheck(pm, env.Username, env.Password, cp.Threshold, cp.CheckInterval, metricSink)
+		check.GraceWindow = cp.GraceWindow
+		check.checkpointID = cp.ID
+		check.spanContext = tracing.ParseTraceParent(cp.TraceParent)
+		runningChecks.add()
+		go scheduleCheck(*check, metricContainer)
+		resumed++
+	}
+	log.Infof("Resumed [%d] out of [%d] checks pending before the restart", resumed, len(pending))
+}
+
+func metricConfigFor(alias string) (MetricConfig, bool) {
+	for _, config := range appConfig.MetricConf {
+		if config.Alias == alias {
+			return config, true
+		}
+	}
+	return MetricConfig{Alias: alias}, false
+}
+
+// memoryCheckpoints keeps the checkpoints in memory, so checks are only
+// resumed within the same process. Used when no checkpoint file is configured.
+type memoryCheckpoints struct {
+	sync.Mutex
+	pending map[uint64]checkpoint
+	lastID  uint64
+}
+
+func newMemoryCheckpoints() *memoryCheckpoints {
+	return &memoryCheckpoints{pending: make(map[uint64]checkpoint)}
+}
+
+func (c *memoryCheckpoints) Save(cp checkpoint) (uint64, error) {
+	c.Lock()
+	defer c.Unlock()
+	return c.add(cp).ID, nil
+}
+
+func (c *memoryCheckpoints) add(cp checkpoint) checkpoint {
+	c.lastID++
+	cp.ID = c.lastID
+	c.pending[cp.ID] = cp
+	return cp
+}
+
+func (c *memoryCheckpoints) Remove(id uint64) error {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.pending, id)
+	return nil
+}
+
+func (c *memoryCheckpoints) Pending() []checkpoint {
+	c.Lock()
+	defer c.Unlock()
+	return c.sorted()
+}
+
+func (c *memoryCheckpoints) sorted() []checkpoint {
+	result := make([]checkpoint, 0, len(c.pending))
+	for _, cp := range c.pending {
+		result = append(result, cp)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+func (c *memoryCheckpoints) Durable() bool {
+	return false
+}
+
+func (c *memoryCheckpoints) Close() error {
+	return nil
+}
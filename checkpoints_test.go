@@ -0,0 +1,182 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCheckpointsSurviveRestart(t *testing.T) {
+	path := tempCheckpointPath(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	store, err := newFileCheckpoints(path)
+	require.NoError(t, err)
+	id1, err := store.Save(checkpoint{UUID: "uuid1", Threshold: 120})
+	require.NoError(t, err)
+	id2, _ := store.Save(checkpoint{UUID: "uuid2"})
+	store.Save(checkpoint{UUID: "uuid3"})
+	require.NoError(t, store.Remove(id2))
+	require.NoError(t, store.Close())
+
+	store, err = newFileCheckpoints(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	pending := store.Pending()
+	require.Len(t, pending, 2)
+	assert.Equal(t, id1, pending[0].ID)
+	assert.Equal(t, "uuid1", pending[0].UUID)
+	assert.Equal(t, 120, pending[0].Threshold)
+	assert.Equal(t, "uuid3", pending[1].UUID)
+
+	id4, _ := store.Save(checkpoint{UUID: "uuid4"})
+	assert.True(t, id4 > pending[1].ID, "ids should continue from the stored ones")
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "uuid2", "removed checkpoints should be compacted out of the file")
+}
+
+func TestUnsupportedCheckpointStore(t *testing.T) {
+	_, err := NewCheckpointStore(CheckpointConfig{Store: "redis"})
+	assert.Error(t, err)
+}
+
+func TestScheduledChecksAreCheckpointedUntilTheyComplete(t *testing.T) {
+	checkpoints = newMemoryCheckpoints()
+	runningChecks = newCheckTracker()
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": fixedResultCheck{true}}
+	results := make(chan PublishMetric, 1)
+
+	check := newShutdownTestCheck(results)
+	saveCheckpoint(&check)
+	require.Len(t, checkpoints.Pending(), 1)
+
+	runningChecks.add()
+	scheduleCheck(check, newMemoryHistory(time.Hour))
+
+	assert.Len(t, results, 1)
+	assert.Empty(t, checkpoints.Pending())
+}
+
+func TestAbortedChecksAreResumedWithASingleResult(t *testing.T) {
+	path := tempCheckpointPath(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer func(saved CheckpointStore) { checkpoints = saved }(checkpoints)
+	store, err := newFileCheckpoints(path)
+	require.NoError(t, err)
+	checkpoints = store
+	runningChecks = newCheckTracker()
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": fixedResultCheck{false}}
+	results := make(chan PublishMetric, 1)
+	history := newMemoryHistory(time.Hour)
+
+	check := newShutdownTestCheck(results)
+	saveCheckpoint(&check)
+	runningChecks.add()
+	go scheduleCheck(check, history)
+	runningChecks.drain(100 * time.Millisecond)
+	require.NoError(t, store.Close())
+
+	assert.Empty(t, results, "the aborted check should be recorded when it is resumed")
+
+	// restart
+	appConfig = &AppConfig{
+		MetricConf: []MetricConfig{{Endpoint: "/content/", Granularity: 2, Alias: "content"}},
+	}
+	store, err = newFileCheckpoints(path)
+	require.NoError(t, err)
+	defer store.Close()
+	checkpoints = store
+	runningChecks = newCheckTracker()
+	metricSink = make(chan PublishMetric, 10)
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": fixedResultCheck{true}}
+	envs := newThreadSafeEnvironments()
+	envs.envMap["env1"] = Environment{"env1", "http://env1.example.org", "", "", ""}
+
+	resumeChecks(store, history, envs)
+	<-runningChecks.idle()
+
+	require.Len(t, metricSink, 1)
+	assert.Equal(t, outcomeOnTime, outcomeOf(<-metricSink))
+	records, _ := history.Query(HistoryQuery{})
+	assert.Len(t, records, 1, "the check should have a single result in the history")
+	assert.Empty(t, checkpoints.Pending())
+}
+
+func TestAbortedChecksWithoutDurableCheckpointsAreRecordedAsAborted(t *testing.T) {
+	checkpoints = newMemoryCheckpoints()
+	runningChecks = newCheckTracker()
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": fixedResultCheck{false}}
+	results := make(chan PublishMetric, 1)
+
+	check := newShutdownTestCheck(results)
+	saveCheckpoint(&check)
+	runningChecks.add()
+	go scheduleCheck(check, newMemoryHistory(time.Hour))
+	runningChecks.drain(100 * time.Millisecond)
+
+	require.Len(t, results, 1)
+	assert.Equal(t, outcomeAborted, outcomeOf(<-results))
+	assert.Empty(t, checkpoints.Pending())
+}
+
+func TestResumeChecks(t *testing.T) {
+	appConfig = &AppConfig{
+		MetricConf: []MetricConfig{{Endpoint: "/content/", Granularity: 2, Alias: "content"}},
+	}
+	runningChecks = newCheckTracker()
+	checkpoints = newMemoryCheckpoints()
+	metricSink = make(chan PublishMetric, 10)
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": fixedResultCheck{true}}
+	history := newMemoryHistory(time.Hour)
+	envs := newThreadSafeEnvironments()
+	envs.envMap["env1"] = Environment{"env1", "http://env1.example.org", "", "", ""}
+
+	now := time.Now()
+	pendingCheck := checkpoint{UUID: "uuid1", Environment: "env1", Alias: "content", Endpoint: "http://env1.example.org/content/", PublishDate: now, Threshold: 60, CheckInterval: 1}
+	expiredCheck := checkpoint{UUID: "uuid2", Environment: "env1", Alias: "content", PublishDate: now.Add(-2 * time.Minute), Threshold: 60, CheckInterval: 1}
+	unknownEnvCheck := checkpoint{UUID: "uuid3", Environment: "env2", Alias: "content", PublishDate: now, Threshold: 60, CheckInterval: 1}
+	for _, cp := range []checkpoint{pendingCheck, expiredCheck, unknownEnvCheck} {
+		checkpoints.Save(cp)
+	}
+
+	resumeChecks(checkpoints, history, envs)
+	<-runningChecks.idle()
+
+	require.Len(t, metricSink, 3)
+	outcomesByUUID := make(map[string]string)
+	for i := 0; i < 3; i++ {
+		pm := <-metricSink
+		outcomesByUUID[pm.UUID] = outcomeOf(pm)
+	}
+	assert.Equal(t, outcomeOnTime, outcomesByUUID["uuid1"])
+	assert.Equal(t, outcomeRestarted, outcomesByUUID["uuid2"])
+	assert.Equal(t, outcomeRestarted, outcomesByUUID["uuid3"])
+	assert.Empty(t, checkpoints.Pending())
+
+	records, _ := history.Query(HistoryQuery{Outcome: outcomeRestarted})
+	assert.Len(t, records, 2)
+}
+
+func TestCheckpointsKeepTheTraceOfTheirCheck(t *testing.T) {
+	traceParent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	check := newShutdownTestCheck(nil)
+	check.spanContext = tracing.ParseTraceParent(traceParent)
+
+	assert.Equal(t, traceParent, newCheckpoint(check).TraceParent, "the resumed check should stay in the trace of its publish")
+	assert.Empty(t, newCheckpoint(newShutdownTestCheck(nil)).TraceParent)
+}
+
+func tempCheckpointPath(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "pam-checkpoints")
+	require.NoError(t, err)
+	return filepath.Join(dir, "pending-checks.db")
+}
@@ -1,6 +1,7 @@
 package checks
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -9,8 +10,8 @@ import (
 )
 
 type DocStoreClient interface {
-	ContentQuery(authority string, identifier string, tid string) (status int, location string, err error)
-	IsUUIDPresent(uuid, tid string) (isPresent bool, err error)
+	ContentQuery(ctx context.Context, authority string, identifier string, tid string) (status int, location string, err error)
+	IsUUIDPresent(ctx context.Context, uuid, tid string) (isPresent bool, err error)
 }
 
 type httpDocStoreClient struct {
@@ -29,7 +30,7 @@ func NewHttpDocStoreClient(docStoreAddress string, httpCaller HttpCaller, userna
 	}
 }
 
-func (c *httpDocStoreClient) ContentQuery(authority string, identifier string, tid string) (status int, location string, err error) {
+func (c *httpDocStoreClient) ContentQuery(ctx context.Context, authority string, identifier string, tid string) (status int, location string, err error) {
 	docStoreUrl, err := url.Parse(c.docStoreAddress + "/content-query")
 	if err != nil {
 		return -1, "", fmt.Errorf("invalid address docStoreAddress=%v", c.docStoreAddress)
@@ -39,7 +40,7 @@ func (c *httpDocStoreClient) ContentQuery(authority string, identifier string, t
 	query.Add("identifierAuthority", authority)
 	docStoreUrl.RawQuery = query.Encode()
 
-	resp, err := c.httpCaller.DoCall(Config{
+	resp, err := c.httpCaller.DoCall(ctx, Config{
 		Url:      docStoreUrl.String(),
 		Username: c.username,
 		Password: c.password,
@@ -54,13 +55,13 @@ func (c *httpDocStoreClient) ContentQuery(authority string, identifier string, t
 	return resp.StatusCode, resp.Header.Get("Location"), nil
 }
 
-func (c *httpDocStoreClient) IsUUIDPresent(uuid, tid string) (isPresent bool, err error) {
+func (c *httpDocStoreClient) IsUUIDPresent(ctx context.Context, uuid, tid string) (isPresent bool, err error) {
 	docStoreUrl, err := url.Parse(c.docStoreAddress + "/content/" + uuid)
 	if err != nil {
 		return false, fmt.Errorf("invalid address docStoreAddress=%v", c.docStoreAddress)
 	}
 
-	resp, err := c.httpCaller.DoCall(Config{
+	resp, err := c.httpCaller.DoCall(ctx, Config{
 		Url:      docStoreUrl.String(),
 		Username: c.username,
 		Password: c.password,
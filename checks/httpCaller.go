@@ -1,17 +1,20 @@
 package checks
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
+	"github.com/Financial-Times/publish-availability-monitor/tracing"
 	"github.com/giantswarm/retry-go"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // httpCaller abstracts http calls
 type HttpCaller interface {
-	DoCall(config Config) (*http.Response, error)
+	DoCall(ctx context.Context, config Config) (*http.Response, error)
 }
 
 // Default implementation of httpCaller
@@ -22,6 +25,7 @@ type defaultHttpCaller struct {
 type Config struct {
 	HttpMethod, Url, Username, Password, ApiKey, TxId, ContentType string
 	Entity                                                         io.Reader
+	Headers                                                        map[string]string //additional request headers
 }
 
 func NewHttpCaller(timeoutSeconds int) HttpCaller {
@@ -44,12 +48,23 @@ func NewHttpCaller(timeoutSeconds int) HttpCaller {
 	return defaultHttpCaller{&client}
 }
 
-// Performs http GET calls using the default http client
-func (c defaultHttpCaller) DoCall(config Config) (resp *http.Response, err error) {
+// Performs http GET calls using the default http client, in a span of ctx
+func (c defaultHttpCaller) DoCall(ctx context.Context, config Config) (resp *http.Response, err error) {
 	if config.HttpMethod == "" {
 		config.HttpMethod = "GET"
 	}
-	req, err := http.NewRequest(config.HttpMethod, config.Url, config.Entity)
+	ctx, span := tracing.StartClient(ctx, "HTTP "+config.HttpMethod)
+	span.SetAttributes(attribute.String("http.method", config.HttpMethod), attribute.String("http.url", config.Url))
+	defer func() {
+		if err != nil {
+			tracing.SetError(span, err)
+		} else {
+			span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+		}
+		span.End()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, config.HttpMethod, config.Url, config.Entity)
 	if config.Username != "" && config.Password != "" {
 		req.SetBasicAuth(config.Username, config.Password)
 	}
@@ -66,7 +81,12 @@ func (c defaultHttpCaller) DoCall(config Config) (resp *http.Response, err error
 		req.Header.Add("Content-Type", config.ContentType)
 	}
 
+	for k, v := range config.Headers {
+		req.Header.Set(k, v)
+	}
+
 	req.Header.Add("User-Agent", "UPP Publish Availability Monitor")
+	tracing.Inject(ctx, req.Header)
 
 	op := func() error {
 		resp, err = c.client.Do(req)
@@ -1,13 +1,20 @@
 package checks
 
 import (
+	"context"
 	"encoding/base64"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/Financial-Times/publish-availability-monitor/tracing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func stubServer(t *testing.T, expectedMethod string, expectedHeaders map[string]string, expectedBody []byte) *httptest.Server {
@@ -49,7 +56,7 @@ func TestUnauthenticated(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewHttpCaller(10)
-	resp, err := httpCaller.DoCall(Config{Url: server.URL})
+	resp, err := httpCaller.DoCall(context.Background(), Config{Url: server.URL})
 	assert.Nil(t, err, "unexpected error")
 
 	assertExpectedResponse(t, resp)
@@ -68,7 +75,7 @@ func TestAuthenticated(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewHttpCaller(10)
-	resp, err := httpCaller.DoCall(Config{Url: server.URL, Username: username, Password: password, ApiKey: apiKey})
+	resp, err := httpCaller.DoCall(context.Background(), Config{Url: server.URL, Username: username, Password: password, ApiKey: apiKey})
 	assert.Nil(t, err, "unexpected error")
 
 	assertExpectedResponse(t, resp)
@@ -84,7 +91,46 @@ func TestTransactionId(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewHttpCaller(10)
-	resp, err := httpCaller.DoCall(Config{Url: server.URL, TxId: txId})
+	resp, err := httpCaller.DoCall(context.Background(), Config{Url: server.URL, TxId: txId})
+	assert.Nil(t, err, "unexpected error")
+
+	assertExpectedResponse(t, resp)
+}
+
+func TestTraceIsPropagated(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	defer func(saved trace.TracerProvider) { otel.SetTracerProvider(saved) }(otel.GetTracerProvider())
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get(tracing.TraceParentHeader)
+	}))
+	defer server.Close()
+
+	ctx, parent := tracing.Start(context.Background(), "mainPreCheck")
+	httpCaller := NewHttpCaller(10)
+	_, err := httpCaller.DoCall(ctx, Config{Url: server.URL, TxId: ConstructPamTxId("tid_myTxId")})
+	assert.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	call := spans[0]
+	assert.Equal(t, "HTTP GET", call.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), call.Parent().SpanID(), "the call should be a child of the span of its context")
+	assert.Equal(t, "00-"+call.SpanContext().TraceID().String()+"-"+call.SpanContext().SpanID().String()+"-01", traceParent)
+}
+
+func TestAdditionalHeaders(t *testing.T) {
+	server := stubServer(t, "GET", map[string]string{
+		"User-Agent":    "UPP Publish Availability Monitor",
+		"Last-Event-ID": "42",
+	}, nil)
+	defer server.Close()
+
+	httpCaller := NewHttpCaller(10)
+	resp, err := httpCaller.DoCall(context.Background(), Config{Url: server.URL, Headers: map[string]string{"Last-Event-ID": "42"}})
 	assert.Nil(t, err, "unexpected error")
 
 	assertExpectedResponse(t, resp)
@@ -104,7 +150,7 @@ func TestRequestWithEntity(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewHttpCaller(10)
-	resp, err := httpCaller.DoCall(Config{HttpMethod: "POST", Url: server.URL, ContentType: contentType, Entity: strings.NewReader(body)})
+	resp, err := httpCaller.DoCall(context.Background(), Config{HttpMethod: "POST", Url: server.URL, ContentType: contentType, Entity: strings.NewReader(body)})
 	assert.Nil(t, err, "unexpected error")
 
 	assertExpectedResponse(t, resp)
@@ -123,7 +169,7 @@ func TestClientDoesRetry(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewHttpCaller(10)
-	_, err := httpCaller.DoCall(Config{HttpMethod: "GET", Url: server.URL})
+	_, err := httpCaller.DoCall(context.Background(), Config{HttpMethod: "GET", Url: server.URL})
 	assert.NoError(t, err)
 	assert.Equal(t, 2, retryCount)
 }
@@ -137,7 +183,7 @@ func TestClientDoesOnlyTwoRetries(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewHttpCaller(10)
-	_, err := httpCaller.DoCall(Config{HttpMethod: "GET", Url: server.URL})
+	_, err := httpCaller.DoCall(context.Background(), Config{HttpMethod: "GET", Url: server.URL})
 	assert.NoError(t, err)
 	assert.Equal(t, 2, retryCount)
 }
@@ -151,7 +197,7 @@ func TestClientDoesNoRetryAfterSuccessfullResponse(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewHttpCaller(10)
-	_, err := httpCaller.DoCall(Config{HttpMethod: "GET", Url: server.URL})
+	_, err := httpCaller.DoCall(context.Background(), Config{HttpMethod: "GET", Url: server.URL})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, retryCount)
 }
@@ -0,0 +1,11 @@
+package checks
+
+// ContainsStatus tells whether statusCode is one of statusCodes.
+func ContainsStatus(statusCodes []int, statusCode int) bool {
+	for _, s := range statusCodes {
+		if s == statusCode {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package checks
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -15,8 +16,8 @@ const (
 var uuidRegex = regexp.MustCompile(uuidPattern)
 
 type UUIDResolver interface {
-	ResolveIdentifier(serviceId, refField, tid string) (string, error)
-	ResolveOriginalUUID(uuid, tid string) (string, error)
+	ResolveIdentifier(ctx context.Context, serviceId, refField, tid string) (string, error)
+	ResolveOriginalUUID(ctx context.Context, uuid, tid string) (string, error)
 }
 
 type httpResolver struct {
@@ -28,25 +29,25 @@ func NewHttpUUIDResolver(client DocStoreClient, brandMappings map[string]string)
 	return &httpResolver{client: client, brandMappings: brandMappings}
 }
 
-func (r *httpResolver) ResolveIdentifier(serviceId, refField, tid string) (string, error) {
+func (r *httpResolver) ResolveIdentifier(ctx context.Context, serviceId, refField, tid string) (string, error) {
 	mappingKey := strings.Split(serviceId, "?")[0]
 	mappingKey = strings.Split(mappingKey, "#")[0]
 	for key, value := range r.brandMappings {
 		if strings.Contains(mappingKey, key) {
 			authority := authorityPrefix + value
 			identifierValue := strings.Split(serviceId, "://")[0] + "://" + key + "/?p=" + refField
-			return r.resolveIdentifier(authority, identifierValue, tid)
+			return r.resolveIdentifier(ctx, authority, identifierValue, tid)
 		}
 	}
 	return "", fmt.Errorf("couldn't find authority in mapping table tid=%v serviceId=%v refField=%v", tid, serviceId, refField)
 }
 
-func (r *httpResolver) ResolveOriginalUUID(uuid, tid string) (string, error) {
+func (r *httpResolver) ResolveOriginalUUID(ctx context.Context, uuid, tid string) (string, error) {
 	if !uuidRegex.MatchString(uuid) {
 		return "", fmt.Errorf("couldn't resolve OriginalUUID=%v for tid=%v because it's not a valid UUID", tid, uuid)
 	}
 
-	isPresent, err := r.client.IsUUIDPresent(uuid, tid)
+	isPresent, err := r.client.IsUUIDPresent(ctx, uuid, tid)
 	if err != nil {
 		return "", fmt.Errorf("couldn't resolve OriginalUUID=%v for tid=%v, error was: %v", uuid, tid, err)
 	}
@@ -57,8 +58,8 @@ func (r *httpResolver) ResolveOriginalUUID(uuid, tid string) (string, error) {
 	return "", nil
 }
 
-func (r *httpResolver) resolveIdentifier(authority string, identifier string, tid string) (string, error) {
-	status, location, err := r.client.ContentQuery(authority, identifier, tid)
+func (r *httpResolver) resolveIdentifier(ctx context.Context, authority string, identifier string, tid string) (string, error) {
+	status, location, err := r.client.ContentQuery(ctx, authority, identifier, tid)
 	if err != nil {
 		return "", err
 	}
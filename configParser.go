@@ -2,8 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"time"
 
+	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/Financial-Times/publish-availability-monitor/feeds"
 	log "github.com/Sirupsen/logrus"
 )
 
@@ -22,5 +26,117 @@ func ParseConfig(configFileName string) (*AppConfig, error) {
 		return nil, err
 	}
 
+	for systemID, source := range conf.ContentSources {
+		if _, err := content.NewGenericSource(source); err != nil {
+			return nil, fmt.Errorf("invalid content source [%v]: %v", systemID, err)
+		}
+	}
+
+	if _, err := newTypeRouter(conf.TypeRules); err != nil {
+		return nil, fmt.Errorf("invalid typeRules: %v", err)
+	}
+
+	for _, metric := range conf.MetricConf {
+		if metric.FeedType != "" && !feeds.IsRegisteredFeedType(metric.FeedType) {
+			return nil, fmt.Errorf("unknown feedType [%v] for the endpoint with alias [%v]", metric.FeedType, metric.Alias)
+		}
+		if metric.Check != nil {
+			if _, err := newGenericCheck(nil, *metric.Check); err != nil {
+				return nil, fmt.Errorf("invalid check for the endpoint with alias [%v]: %v", metric.Alias, err)
+			}
+		}
+		for _, field := range metric.VerifyFields {
+			if !validType(content.VerifiableFields, field) {
+				return nil, fmt.Errorf("unknown field [%v] to verify for the endpoint with alias [%v]", field, metric.Alias)
+			}
+		}
+	}
+
 	return &conf, nil
 }
+
+// feedType returns the type of the notifications feed consumed by the checks at
+// the endpoint of metric, which is derived from the alias when it is not
+// configured, or an empty string if the endpoint has no feed.
+func (metric MetricConfig) feedType() string {
+	if metric.FeedType != "" {
+		return metric.FeedType
+	}
+	return feeds.FeedTypeOf(metric.Alias)
+}
+
+// configureFeed applies the feed settings of metric to f, before it is started.
+func configureFeed(f feeds.Feed, metric MetricConfig) {
+	if push, ok := f.(*feeds.NotificationsPushFeed); ok && metric.HeartbeatTimeout > 0 {
+		push.SetHeartbeatTimeout(time.Duration(metric.HeartbeatTimeout) * time.Second)
+	}
+	if kafka, ok := f.(*feeds.NotificationsKafkaFeed); ok && metric.QueueConf != nil {
+		kafka.SetQueueConfig(*metric.QueueConf)
+	}
+	if buffered, ok := f.(feeds.BufferedFeed); ok {
+		buffered.SetBufferLimits(metric.MaxBufferedUUIDs, metric.MaxNotificationsPerUUID)
+	}
+}
+
+// thresholdFor returns the publish SLA, in seconds, of content of the given type
+// at the endpoint of metric: the threshold of the endpoint if it has one,
+// otherwise the threshold of the content type, otherwise the global threshold.
+func (c *AppConfig) thresholdFor(metric MetricConfig, contentType string) int {
+	if metric.Threshold > 0 {
+		return metric.Threshold
+	}
+	if t, found := c.ContentTypeThresholds[contentType]; found && t > 0 {
+		return t
+	}
+	return c.Threshold
+}
+
+// maxThresholdFor returns the longest publish SLA of content of the given type,
+// across all the endpoints it is checked at.
+func (c *AppConfig) maxThresholdFor(contentType string) int {
+	max := 0
+	for _, metric := range c.MetricConf {
+		if validType(metric.ContentTypes, contentType) {
+			if t := c.thresholdFor(metric, contentType); t > max {
+				max = t
+			}
+		}
+	}
+	if max == 0 {
+		return c.thresholdFor(MetricConfig{}, contentType)
+	}
+	return max
+}
+
+// maxThresholdAtAnyEndpoint returns the longest publish SLA of content of the
+// given type if it was checked at every endpoint.
+func (c *AppConfig) maxThresholdAtAnyEndpoint(contentType string) int {
+	max := c.thresholdFor(MetricConfig{}, contentType)
+	for _, metric := range c.MetricConf {
+		if t := c.thresholdFor(metric, contentType); t > max {
+			max = t
+		}
+	}
+	return max
+}
+
+// maxThresholdAt returns the longest publish SLA of the content checked at the
+// endpoint of metric.
+func (c *AppConfig) maxThresholdAt(metric MetricConfig) int {
+	max := c.thresholdFor(metric, "")
+	for _, contentType := range metric.ContentTypes {
+		if t := c.thresholdFor(metric, contentType); t > max {
+			max = t
+		}
+	}
+	return max
+}
+
+// checkIntervalFor returns how often, in seconds, the endpoint of metric is
+// checked for content with the given SLA.
+func checkIntervalFor(metric MetricConfig, threshold int) int {
+	if metric.Granularity <= 0 || threshold < metric.Granularity {
+		return 1
+	}
+	return threshold / metric.Granularity
+}
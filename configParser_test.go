@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Financial-Times/publish-availability-monitor/feeds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdFor(t *testing.T) {
+	config := &AppConfig{
+		Threshold:             120,
+		ContentTypeThresholds: map[string]int{"Image": 60},
+	}
+
+	var testCases = []struct {
+		metric      MetricConfig
+		contentType string
+		expected    int
+	}{
+		{MetricConfig{}, "EOM::CompoundStory", 120},
+		{MetricConfig{}, "Image", 60},
+		{MetricConfig{Threshold: 300}, "Image", 300},
+		{MetricConfig{Threshold: 300}, "EOM::CompoundStory", 300},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, config.thresholdFor(tc.metric, tc.contentType), "threshold of %v for [%s]", tc.metric, tc.contentType)
+	}
+}
+
+func TestMaxThresholdFor(t *testing.T) {
+	config := &AppConfig{
+		Threshold:             120,
+		ContentTypeThresholds: map[string]int{"Image": 60},
+		MetricConf: []MetricConfig{
+			{Alias: "content"},
+			{Alias: "S3", Threshold: 90, ContentTypes: []string{"Image"}},
+			{Alias: "lists", Threshold: 600, ContentTypes: []string{"EOM::WebContainer"}},
+		},
+	}
+
+	assert.Equal(t, 90, config.maxThresholdFor("Image"))
+	assert.Equal(t, 120, config.maxThresholdFor("EOM::CompoundStory"))
+	assert.Equal(t, 600, config.maxThresholdFor("EOM::WebContainer"))
+	assert.Equal(t, 120, config.maxThresholdAt(config.MetricConf[0]))
+	assert.Equal(t, 600, config.maxThresholdAt(config.MetricConf[2]))
+}
+
+func TestCheckIntervalFor(t *testing.T) {
+	assert.Equal(t, 3, checkIntervalFor(MetricConfig{Granularity: 40}, 120))
+	assert.Equal(t, 1, checkIntervalFor(MetricConfig{Granularity: 40}, 30))
+	assert.Equal(t, 1, checkIntervalFor(MetricConfig{}, 120))
+}
+
+func TestParseConfigRejectsUnknownFeedTypes(t *testing.T) {
+	file, err := ioutil.TempFile("", "config")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(`{"metricConfig": [{"alias": "page-notifications", "feedType": "Page-Notifications"}]}`)
+	require.NoError(t, err)
+	file.Close()
+
+	_, err = ParseConfig(file.Name())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Page-Notifications")
+}
+
+func TestParseConfigRejectsUnknownFieldsToVerify(t *testing.T) {
+	file, err := ioutil.TempFile("", "config")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(`{"metricConfig": [{"alias": "content", "verifyFields": ["title", "standfirst"]}]}`)
+	require.NoError(t, err)
+	file.Close()
+
+	_, err = ParseConfig(file.Name())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "standfirst")
+}
+
+func TestParseConfigRejectsInvalidChecks(t *testing.T) {
+	file, err := ioutil.TempFile("", "config")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(`{"metricConfig": [{"alias": "annotations", "check": {"uuidPath": "$.annotations[first].id"}}]}`)
+	require.NoError(t, err)
+	file.Close()
+
+	_, err = ParseConfig(file.Name())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "annotations")
+}
+
+func TestFeedTypeOfMetric(t *testing.T) {
+	assert.Equal(t, feeds.NotificationsPush, MetricConfig{Alias: "notifications-push"}.feedType())
+	assert.Equal(t, feeds.NotificationsPull, MetricConfig{Alias: "page-notifications", FeedType: feeds.NotificationsPull}.feedType())
+	assert.Equal(t, "", MetricConfig{Alias: "content"}.feedType())
+}
+
+func TestRegisterFeedChecks(t *testing.T) {
+	defer func(saved map[string]EndpointSpecificCheck) { endpointSpecificChecks = saved }(endpointSpecificChecks)
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": ContentCheck{}}
+
+	registerFeedChecks([]MetricConfig{
+		{Alias: "content", FeedType: feeds.NotificationsPull},
+		{Alias: "page-notifications", FeedType: feeds.NotificationsPull},
+		{Alias: "lists"},
+	})
+
+	assert.IsType(t, ContentCheck{}, endpointSpecificChecks["content"], "existing checks should be kept")
+	check, ok := endpointSpecificChecks["page-notifications"].(NotificationsCheck)
+	require.True(t, ok, "expected a NotificationsCheck")
+	assert.Equal(t, "page-notifications", check.feedName)
+	assert.NotContains(t, endpointSpecificChecks, "lists")
+}
@@ -2,6 +2,7 @@ package content
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -13,7 +14,7 @@ import (
 // Content is the interface for different type of contents from different CMSs.
 type Content interface {
 	Initialize(binaryContent []byte) Content
-	Validate(externalValidationEndpoint string, txID string, username string, password string) ValidationResponse
+	Validate(ctx context.Context, externalValidationEndpoint string, txID string, username string, password string) ValidationResponse
 	GetType() string
 	GetUUID() string
 }
@@ -39,13 +40,13 @@ func init() {
 	httpCaller = checks.NewHttpCaller(10)
 }
 
-func doExternalValidation(p validationParam, validCheck func(int) bool, deletedCheck func(...int) bool) ValidationResponse {
+func doExternalValidation(ctx context.Context, p validationParam, validCheck func(int) bool, deletedCheck func(...int) bool) ValidationResponse {
 	if p.validationURL == "" {
 		log.Warnf("External validation for content uuid=[%s] transaction_id=[%s]. Validation endpoint URL is missing for content type=[%s]", p.uuid, p.txID, p.contentType)
 		return ValidationResponse{false, deletedCheck()}
 	}
 
-	resp, err := httpCaller.DoCall(checks.Config{
+	resp, err := httpCaller.DoCall(ctx, checks.Config{
 		HttpMethod: "POST", Url: p.validationURL, Username: p.username, Password: p.password,
 		TxId:        checks.ConstructPamTxId(p.txID),
 		ContentType: "application/json", Entity: bytes.NewReader(p.binaryContent)})
@@ -0,0 +1,146 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/Financial-Times/publish-availability-monitor/jsonpath"
+	"github.com/Financial-Times/uuid-utils-go"
+	log "github.com/Sirupsen/logrus"
+)
+
+// SourceConfig describes the JSON messages of a CMS which has no Content
+// implementation of its own. The paths are JSON paths in the message, ex. "$.uuid".
+type SourceConfig struct {
+	ContentType        string `json:"contentType,omitempty"`        //type of all the content of the CMS, ex. "LiveBlog"
+	TypePath           string `json:"typePath,omitempty"`           //or the type of each content, ex. "$.type"
+	UUIDPath           string `json:"uuidPath"`                     //ex. "$.uuid"
+	DeletedPath        string `json:"deletedPath,omitempty"`        //true or "true" for content marked deleted, ex. "$.deleted"
+	ValidStatusCodes   []int  `json:"validStatusCodes,omitempty"`   //statuses of the external validation of valid content, default [200]
+	DeletedStatusCodes []int  `json:"deletedStatusCodes,omitempty"` //statuses of the external validation of content marked deleted, which is valid too
+}
+
+// GenericSource unmarshals the messages of a CMS configured with a SourceConfig.
+type GenericSource struct {
+	config      SourceConfig
+	typePath    jsonpath.Path
+	uuidPath    jsonpath.Path
+	deletedPath jsonpath.Path
+}
+
+// NewGenericSource returns the GenericSource described by config, or an error when config is invalid.
+func NewGenericSource(config SourceConfig) (*GenericSource, error) {
+	if config.ContentType == "" && config.TypePath == "" {
+		return nil, errors.New("either contentType or typePath is required")
+	}
+	if config.UUIDPath == "" {
+		return nil, errors.New("uuidPath is required")
+	}
+	if len(config.ValidStatusCodes) == 0 {
+		config.ValidStatusCodes = []int{200}
+	}
+
+	s := &GenericSource{config: config}
+	var err error
+	if s.typePath, err = jsonpath.Parse(config.TypePath); err != nil {
+		return nil, err
+	}
+	if s.uuidPath, err = jsonpath.Parse(config.UUIDPath); err != nil {
+		return nil, err
+	}
+	s.deletedPath, err = jsonpath.Parse(config.DeletedPath)
+	return s, err
+}
+
+// Unmarshal returns the content of a message of the CMS.
+func (s *GenericSource) Unmarshal(binaryContent []byte) (Content, error) {
+	var doc interface{}
+	if err := json.Unmarshal(binaryContent, &doc); err != nil {
+		return nil, err
+	}
+
+	content := GenericContent{
+		Type:   s.config.ContentType,
+		source: s,
+	}
+	if s.typePath != nil {
+		if content.Type = firstString(s.typePath.Lookup(doc)); content.Type == "" {
+			return nil, fmt.Errorf("no content type at [%v]", s.typePath)
+		}
+	}
+	if content.UUID = firstString(s.uuidPath.Lookup(doc)); content.UUID == "" {
+		return nil, fmt.Errorf("no UUID at [%v]", s.uuidPath)
+	}
+	for _, deleted := range s.deletedPath.Lookup(doc) {
+		if deleted == true || deleted == "true" {
+			content.Deleted = true
+		}
+	}
+	return content.Initialize(binaryContent), nil
+}
+
+func firstString(values []interface{}) string {
+	for _, v := range values {
+		if s, ok := v.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// GenericContent is the content of a CMS configured with a SourceConfig.
+type GenericContent struct {
+	UUID          string
+	Type          string
+	Deleted       bool
+	BinaryContent []byte //This field is for internal application usage
+	source        *GenericSource
+}
+
+func (g GenericContent) Initialize(binaryContent []byte) Content {
+	g.BinaryContent = binaryContent
+	return g
+}
+
+func (g GenericContent) Validate(ctx context.Context, externalValidationEndpoint string, txID string, username string, password string) ValidationResponse {
+	if uuidutils.ValidateUUID(g.GetUUID()) != nil {
+		log.Warnf("%s content invalid: invalid UUID: [%s]", g.GetType(), g.GetUUID())
+		return ValidationResponse{IsValid: false, IsMarkedDeleted: g.isMarkedDeleted()}
+	}
+
+	validationParam := validationParam{
+		g.BinaryContent,
+		externalValidationEndpoint,
+		username,
+		password,
+		txID,
+		g.GetUUID(),
+		g.GetType(),
+	}
+
+	return doExternalValidation(
+		ctx,
+		validationParam,
+		g.isValid,
+		g.isMarkedDeleted,
+	)
+}
+
+func (g GenericContent) isValid(status int) bool {
+	return checks.ContainsStatus(g.source.config.ValidStatusCodes, status) || checks.ContainsStatus(g.source.config.DeletedStatusCodes, status)
+}
+
+func (g GenericContent) isMarkedDeleted(status ...int) bool {
+	return g.Deleted || (len(status) == 1 && checks.ContainsStatus(g.source.config.DeletedStatusCodes, status[0]))
+}
+
+func (g GenericContent) GetType() string {
+	return g.Type
+}
+
+func (g GenericContent) GetUUID() string {
+	return g.UUID
+}
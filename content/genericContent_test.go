@@ -0,0 +1,70 @@
+package content
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenericSourceUnmarshalsTheConfiguredFields(t *testing.T) {
+	source, err := NewGenericSource(SourceConfig{TypePath: "$.type", UUIDPath: "$.identifiers[*].uuid", DeletedPath: "deleted"})
+	require.NoError(t, err)
+
+	c, err := source.Unmarshal([]byte(`{"type": "LiveBlog", "identifiers": [{"authority": "cms"}, {"uuid": "` + validUUID + `"}], "deleted": "true"}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "LiveBlog", c.GetType())
+	assert.Equal(t, validUUID, c.GetUUID())
+	generic, ok := c.(GenericContent)
+	require.True(t, ok, "expected a GenericContent")
+	assert.True(t, generic.Deleted)
+	assert.NotEmpty(t, generic.BinaryContent)
+}
+
+func TestGenericSourceRejectsMessagesWithoutUUID(t *testing.T) {
+	source, err := NewGenericSource(SourceConfig{ContentType: "Podcast", UUIDPath: "$.uuid"})
+	require.NoError(t, err)
+
+	_, err = source.Unmarshal([]byte(`{"id": "` + validUUID + `"}`))
+	assert.Error(t, err)
+	_, err = source.Unmarshal([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestInvalidSourceConfigs(t *testing.T) {
+	_, err := NewGenericSource(SourceConfig{UUIDPath: "$.uuid"})
+	assert.Error(t, err, "the content type should be required")
+	_, err = NewGenericSource(SourceConfig{ContentType: "Podcast"})
+	assert.Error(t, err, "the UUID path should be required")
+	_, err = NewGenericSource(SourceConfig{ContentType: "Podcast", UUIDPath: "$.uuid", DeletedPath: "$.status[deleted]"})
+	assert.Error(t, err)
+}
+
+func TestGenericContentValidationStatusCodes(t *testing.T) {
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	source, err := NewGenericSource(SourceConfig{ContentType: "Podcast", UUIDPath: "$.uuid", DeletedStatusCodes: []int{http.StatusGone}})
+	require.NoError(t, err)
+	c, err := source.Unmarshal([]byte(`{"uuid": "` + validUUID + `"}`))
+	require.NoError(t, err)
+
+	valRes := c.Validate(context.Background(), ts.URL, "tid_1234", "", "")
+	assert.True(t, valRes.IsValid)
+	assert.False(t, valRes.IsMarkedDeleted)
+
+	status = http.StatusGone
+	valRes = c.Validate(context.Background(), ts.URL, "tid_1234", "", "")
+	assert.True(t, valRes.IsValid)
+	assert.True(t, valRes.IsMarkedDeleted)
+
+	status = http.StatusUnprocessableEntity
+	assert.False(t, c.Validate(context.Background(), ts.URL, "tid_1234", "", "").IsValid)
+}
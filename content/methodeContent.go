@@ -1,8 +1,15 @@
 package content
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/xml"
 	"net/http"
+	"net/url"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
 )
 
 // EomFile models Methode content
@@ -35,6 +42,16 @@ type Attributes struct {
 	Category            string   `xml:"WiresIndexing>category"`
 	IsDeleted           bool     `xml:"OutputChannels>DIFTcom>DIFTcomMarkDeleted"`
 	OriginalUUID        string   `xml:"EditorialNotes>OriginalUUID"`
+	MasterImageFileRef  string   `xml:"EditorialDisplayIndexing>DIMasterImgFileRef"`
+}
+
+// eomFileValue models the fields of methode stories which are verified
+type eomFileValue struct {
+	Headline string `xml:"lead>lead-headline>headline>ln"`
+	Byline   string `xml:"story>text>byline>author-name"`
+	Body     struct {
+		Content string `xml:",innerxml"`
+	} `xml:"story>text>body"`
 }
 
 func (eomfile EomFile) Initialize(binaryContent []byte) Content {
@@ -42,7 +59,7 @@ func (eomfile EomFile) Initialize(binaryContent []byte) Content {
 	return eomfile
 }
 
-func (eomfile EomFile) Validate(externalValidationEndpoint string, txID string, username string, password string) ValidationResponse {
+func (eomfile EomFile) Validate(ctx context.Context, externalValidationEndpoint string, txID string, username string, password string) ValidationResponse {
 	validationParam := validationParam{
 		eomfile.BinaryContent,
 		externalValidationEndpoint,
@@ -54,6 +71,7 @@ func (eomfile EomFile) Validate(externalValidationEndpoint string, txID string,
 	}
 
 	return doExternalValidation(
+		ctx,
 		validationParam,
 		eomfile.isValid,
 		eomfile.isMarkedDeleted,
@@ -75,3 +93,43 @@ func (eomfile EomFile) GetType() string {
 func (eomfile EomFile) GetUUID() string {
 	return eomfile.UUID
 }
+
+// ExpectedFields returns the headline, byline and body hash of a story, and the
+// UUID of its master image.
+func (eomfile EomFile) ExpectedFields() map[string]string {
+	fields := make(map[string]string)
+
+	value, err := base64.StdEncoding.DecodeString(eomfile.Value)
+	if err != nil {
+		log.Warnf("Cannot decode the value of content uuid=[%s]: [%v]", eomfile.UUID, err)
+		return fields
+	}
+	var story eomFileValue
+	if err := unmarshalLenient(value, &story); err != nil {
+		log.Warnf("Cannot parse the value of content uuid=[%s]: [%v]", eomfile.UUID, err)
+		return fields
+	}
+	addField(fields, TitleField, story.Headline)
+	addField(fields, BylineField, story.Byline)
+	if strings.TrimSpace(story.Body.Content) != "" {
+		fields[BodyHashField] = BodyHash(story.Body.Content)
+	}
+
+	var attributes Attributes
+	if err := unmarshalLenient([]byte(eomfile.Attributes), &attributes); err != nil {
+		log.Warnf("Cannot parse the attributes of content uuid=[%s]: [%v]", eomfile.UUID, err)
+		return fields
+	}
+	if imageRef, err := url.Parse(strings.TrimSpace(attributes.MasterImageFileRef)); err == nil {
+		addField(fields, MainImageField, imageRef.Query().Get("uuid"))
+	}
+	return fields
+}
+
+// unmarshalLenient unmarshals methode XML, which may use HTML entities.
+func unmarshalLenient(data []byte, v interface{}) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	return decoder.Decode(v)
+}
@@ -1,6 +1,7 @@
 package content
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -22,7 +23,7 @@ func (m *MockIResolver) ResolveIdentifier(serviceId, refField, tid string) (stri
 }
 
 func TestIsEomfileValid_EmptyValidationURL_Invalid(t *testing.T) {
-	valRes := eomfileWithInvalidContentType.Validate("", validUUID, "", "")
+	valRes := eomfileWithInvalidContentType.Validate(context.Background(), "", validUUID, "", "")
 	if valRes.IsValid {
 		t.Error("Eomfile with empty validation URL marked as valid")
 	}
@@ -36,7 +37,7 @@ func TestIsEomfileValid_ExternalValidationTrue_Valid(t *testing.T) {
 		//return OK
 	}))
 	defer ts.Close()
-	valRes := validCompoundStory.Validate(ts.URL+"/content-transform", "tid_"+txId, "", "")
+	valRes := validCompoundStory.Validate(context.Background(), ts.URL+"/content-transform", "tid_"+txId, "", "")
 	if !valRes.IsValid {
 		t.Error("Valid CompoundStory marked as invalid!")
 	}
@@ -50,7 +51,7 @@ func TestIsEomfileValid_ExternalValidationFalseStatusCode418_Invalid(t *testing.
 		w.WriteHeader(http.StatusTeapot)
 	}))
 	defer ts.Close()
-	valRes := validCompoundStory.Validate(ts.URL+"/content-transform", "tid_"+txId, "", "")
+	valRes := validCompoundStory.Validate(context.Background(), ts.URL+"/content-transform", "tid_"+txId, "", "")
 	if valRes.IsValid {
 		t.Error("Valid CompoundStory regarded as invalid by external validation marked as valid!")
 	}
@@ -62,7 +63,7 @@ func TestIsEomfileValid_ExternalValidationFalseStatusCode422_Invalid(t *testing.
 		w.WriteHeader(http.StatusUnprocessableEntity)
 	}))
 	defer ts.Close()
-	valRes := validCompoundStory.Validate(ts.URL+"/map", "", "", "")
+	valRes := validCompoundStory.Validate(context.Background(), ts.URL+"/map", "", "", "")
 	if valRes.IsValid {
 		t.Error("Valid CompoundStory regarded as invalid by external validation marked as valid!")
 	}
@@ -91,7 +92,7 @@ func TestIsMarkedDeleted_CompoundStory_True(t *testing.T) {
 		w.WriteHeader(http.StatusNotFound)
 	}))
 	defer ts.Close()
-	valRes := compoundStoryMarkedDeletedTrue.Validate(ts.URL+"/map", "", "", "")
+	valRes := compoundStoryMarkedDeletedTrue.Validate(context.Background(), ts.URL+"/map", "", "", "")
 
 	if !valRes.IsMarkedDeleted {
 		t.Error("Expected True, the compound story IS marked deleted")
@@ -104,7 +105,7 @@ func TestIsMarkedDeleted_CompoundStory_False(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	}))
 	defer ts.Close()
-	valRes := compoundStoryMarkedDeletedFalse.Validate(ts.URL+"/map", "", "", "")
+	valRes := compoundStoryMarkedDeletedFalse.Validate(context.Background(), ts.URL+"/map", "", "", "")
 
 	if valRes.IsMarkedDeleted {
 		t.Error("Expected False, the compound story IS NOT marked deleted")
@@ -117,7 +118,7 @@ func TestIsMarkedDeleted_Story_True(t *testing.T) {
 		w.WriteHeader(http.StatusNotFound)
 	}))
 	defer ts.Close()
-	valRes := storyMarkedDeletedTrue.Validate(ts.URL+"/map", "", "", "")
+	valRes := storyMarkedDeletedTrue.Validate(context.Background(), ts.URL+"/map", "", "", "")
 
 	if !valRes.IsMarkedDeleted {
 		t.Error("Expected True, the story IS marked deleted")
@@ -130,7 +131,7 @@ func TestIsMarkedDeleted_Story_False(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	}))
 	defer ts.Close()
-	valRes := storyMarkedDeletedFalse.Validate(ts.URL+"/map", "", "", "")
+	valRes := storyMarkedDeletedFalse.Validate(context.Background(), ts.URL+"/map", "", "", "")
 
 	if valRes.IsMarkedDeleted {
 		t.Error("Expected False, the story IS NOT marked deleted")
@@ -0,0 +1,62 @@
+package content
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"strings"
+)
+
+// The fields of a content which can be verified against the content served by the read APIs.
+const (
+	TitleField     = "title"
+	BodyHashField  = "bodyHash" //hash of the text of the body, see BodyHash
+	BylineField    = "byline"
+	MainImageField = "mainImage" //UUID of the main image
+)
+
+// VerifiableFields are the fields which can be verified.
+var VerifiableFields = []string{TitleField, BodyHashField, BylineField, MainImageField}
+
+// VerifiableContent is implemented by the contents whose fields can be
+// extracted from the CMS message, to verify what the read APIs serve.
+type VerifiableContent interface {
+	// ExpectedFields returns the fields found in the message, by name
+	ExpectedFields() map[string]string
+}
+
+// BodyHash returns the hash of the text of an XML or HTML body, ignoring its
+// markup and whitespace, so that a body sent by a CMS and the body transformed
+// for the read APIs have the same hash when they read the same.
+func BodyHash(body string) string {
+	hash := sha256.Sum256([]byte(strings.Join(strings.Fields(textOf(body)), "")))
+	return hex.EncodeToString(hash[:])
+}
+
+// textOf returns the character data of body. Processing instructions, comments
+// and whatever cannot be parsed are left out.
+func textOf(body string) string {
+	decoder := xml.NewDecoder(strings.NewReader("<body>" + body + "</body>"))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			// io.EOF, or the rest of the body cannot be parsed
+			return text.String()
+		}
+		if data, ok := token.(xml.CharData); ok {
+			text.Write(data)
+		}
+	}
+}
+
+// addField adds the field to fields, when its value is not blank.
+func addField(fields map[string]string, field string, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		fields[field] = value
+	}
+}
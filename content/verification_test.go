@@ -0,0 +1,51 @@
+package content
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyHashIgnoresMarkupAndWhitespace(t *testing.T) {
+	cmsBody := "<p>Hello <a href=\"https://www.ft.com/topics/places/World\">world</a>.</p>\n<p>Bye&nbsp;now<?EM-dummyText [Insert text here]?></p>"
+	readBody := "<body><p>Hello <ft-concept id=\"1\">world</ft-concept>.</p><p>Bye now</p></body>"
+
+	assert.Equal(t, BodyHash(cmsBody), BodyHash(readBody))
+	assert.NotEqual(t, BodyHash(cmsBody), BodyHash("<body><p>Hello world!</p><p>Bye now</p></body>"))
+}
+
+func TestEomFileExpectedFields(t *testing.T) {
+	var article EomFile
+	require.NoError(t, json.Unmarshal(loadBytesForFile(t, "methode_article.json"), &article))
+
+	fields := article.ExpectedFields()
+
+	assert.Equal(t, "Putin opponent Alexei Navalny found guilty of embezzlement", fields[TitleField])
+	assert.Equal(t, "Kathrin Hille in Kirov", fields[BylineField])
+	assert.Equal(t, "7baa33ba-eded-11e6-ba01-119a44939bb6", fields[MainImageField])
+	assert.Len(t, fields[BodyHashField], 64)
+}
+
+func TestEomFileWithoutStoryHasNoExpectedFields(t *testing.T) {
+	assert.Empty(t, EomFile{UUID: validUUID, Value: "not base64"}.ExpectedFields())
+}
+
+func TestWordPressMessageExpectedFields(t *testing.T) {
+	var msg WordPressMessage
+	require.NoError(t, json.Unmarshal([]byte(`{"status": "ok", "post": {"uuid": "`+validUUID+`",
+		"title": "Markets &#8217; week", "content": "<p>Stocks rose.</p>", "author": {"name": "Jane Doe"}}}`), &msg))
+
+	fields := msg.ExpectedFields()
+
+	assert.Equal(t, "Markets ’ week", fields[TitleField])
+	assert.Equal(t, "Jane Doe", fields[BylineField])
+	assert.Equal(t, BodyHash("<body><p>Stocks rose.</p></body>"), fields[BodyHashField])
+	assert.NotContains(t, fields, MainImageField)
+}
+
+func TestVideoExpectedFields(t *testing.T) {
+	assert.Equal(t, map[string]string{TitleField: "Markets now"}, Video{ID: validUUID, Name: " Markets now "}.ExpectedFields())
+	assert.Empty(t, Video{ID: validUUID}.ExpectedFields())
+}
@@ -1,6 +1,7 @@
 package content
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/Financial-Times/uuid-utils-go"
@@ -11,6 +12,7 @@ const videoType = "video"
 
 type Video struct {
 	ID            string `json:"id"`
+	Name          string `json:"name,omitempty"` //title of the video
 	Deleted       bool   `json:"deleted,omitempty"`
 	BinaryContent []byte `json:"-"` //This field is for internal application usage
 }
@@ -20,7 +22,7 @@ func (video Video) Initialize(binaryContent []byte) Content {
 	return video
 }
 
-func (video Video) Validate(externalValidationEndpoint string, txId string, username string, password string) ValidationResponse {
+func (video Video) Validate(ctx context.Context, externalValidationEndpoint string, txId string, username string, password string) ValidationResponse {
 	if uuidutils.ValidateUUID(video.GetUUID()) != nil {
 		log.Warnf("Video invalid: invalid UUID: [%s]", video.GetUUID())
 		return ValidationResponse{IsValid: false, IsMarkedDeleted: video.isMarkedDeleted()}
@@ -37,6 +39,7 @@ func (video Video) Validate(externalValidationEndpoint string, txId string, user
 	}
 
 	return doExternalValidation(
+		ctx,
 		validationParam,
 		video.isValid,
 		video.isMarkedDeleted,
@@ -58,3 +61,10 @@ func (video Video) GetType() string {
 func (video Video) GetUUID() string {
 	return video.ID
 }
+
+// ExpectedFields returns the title of the video.
+func (video Video) ExpectedFields() map[string]string {
+	fields := make(map[string]string)
+	addField(fields, TitleField, video.Name)
+	return fields
+}
@@ -1,6 +1,7 @@
 package content
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -29,14 +30,14 @@ func TestIsVideoValid_Valid(t *testing.T) {
 		assert.Equal(t, videoValid.BinaryContent, reqBody)
 	}))
 
-	validationResponse := videoValid.Validate(testServer.URL+"/map", txId, "", "")
+	validationResponse := videoValid.Validate(context.Background(), testServer.URL+"/map", txId, "", "")
 	assert.True(t, validationResponse.IsValid, "Video should be valid.")
 }
 
 func TestIsVideoValid_NoId(t *testing.T) {
 	var videoNoId = Video{}
 
-	validationResponse := videoNoId.Validate("", "", "", "")
+	validationResponse := videoNoId.Validate(context.Background(), "", "", "", "")
 	assert.False(t, validationResponse.IsValid, "Video should be invalid as it has no Id.")
 }
 
@@ -61,7 +62,7 @@ func TestIsVideoValid_failedExternalValidation(t *testing.T) {
 		w.WriteHeader(http.StatusBadRequest)
 	}))
 
-	validationResponse := videoInvalid.Validate(testServer.URL+"/map", txId, "", "")
+	validationResponse := videoInvalid.Validate(context.Background(), testServer.URL+"/map", txId, "", "")
 	assert.False(t, validationResponse.IsMarkedDeleted, "Video should fail external validation.")
 }
 
@@ -71,6 +72,6 @@ func TestIsDeleted(t *testing.T) {
 		Deleted: true,
 	}
 
-	validationResponse := videoNoDates.Validate("", "", "", "")
+	validationResponse := videoNoDates.Validate(context.Background(), "", "", "", "")
 	assert.True(t, validationResponse.IsMarkedDeleted, "Video should be evaluated as deleted.")
 }
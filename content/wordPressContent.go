@@ -1,6 +1,8 @@
 package content
 
 import (
+	"context"
+	"html"
 	"net/http"
 )
 
@@ -16,11 +18,19 @@ type WordPressMessage struct {
 }
 
 // Post models WordPress content
-// neglect unused fields (e.g. id, slug, title, content, etc)
+// neglect unused fields (e.g. id, slug, excerpt, etc)
 type Post struct {
-	Type string `json:"type"`
-	UUID string `json:"uuid"`
-	Url  string `json:"url"`
+	Type    string `json:"type"`
+	UUID    string `json:"uuid"`
+	Url     string `json:"url"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Author  Author `json:"author"`
+}
+
+// Author models the author of a WordPress post
+type Author struct {
+	Name string `json:"name"`
 }
 
 func (wordPressMessage WordPressMessage) Initialize(binaryContent []byte) Content {
@@ -28,7 +38,7 @@ func (wordPressMessage WordPressMessage) Initialize(binaryContent []byte) Conten
 	return wordPressMessage
 }
 
-func (wordPressMessage WordPressMessage) Validate(extValEndpoint string, txId string, username string, password string) ValidationResponse {
+func (wordPressMessage WordPressMessage) Validate(ctx context.Context, extValEndpoint string, txId string, username string, password string) ValidationResponse {
 	validationParam := validationParam{
 		wordPressMessage.BinaryContent,
 		extValEndpoint,
@@ -40,6 +50,7 @@ func (wordPressMessage WordPressMessage) Validate(extValEndpoint string, txId st
 	}
 
 	return doExternalValidation(
+		ctx,
 		validationParam,
 		wordPressMessage.isValid,
 		wordPressMessage.isMarkedDeleted,
@@ -61,3 +72,15 @@ func (wordPressMessage WordPressMessage) GetType() string {
 func (wordPressMessage WordPressMessage) GetUUID() string {
 	return wordPressMessage.Post.UUID
 }
+
+// ExpectedFields returns the title, author and body hash of the post.
+func (wordPressMessage WordPressMessage) ExpectedFields() map[string]string {
+	fields := make(map[string]string)
+	post := wordPressMessage.Post
+	addField(fields, TitleField, html.UnescapeString(post.Title))
+	addField(fields, BylineField, html.UnescapeString(post.Author.Name))
+	if post.Content != "" {
+		fields[BodyHashField] = BodyHash(post.Content)
+	}
+	return fields
+}
@@ -1,6 +1,7 @@
 package content
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -34,7 +35,7 @@ func TestIsValid_ExternalValidationInvalidArticle422(t *testing.T) {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 	}))
 
-	valResp := wordpressMessage.Validate(testServer.URL+"/map", txId, "", "")
+	valResp := wordpressMessage.Validate(context.Background(), testServer.URL+"/map", txId, "", "")
 	if valResp.IsValid {
 		t.Error("Wordpress should fail external validation.")
 	}
@@ -64,7 +65,7 @@ func TestIsValid_ExternalValidationMarkedAsDeleted404(t *testing.T) {
 		w.WriteHeader(http.StatusNotFound)
 	}))
 
-	valResp := wordpressMessage.Validate(testServer.URL+"/map", txId, "", "")
+	valResp := wordpressMessage.Validate(context.Background(), testServer.URL+"/map", txId, "", "")
 	if !valResp.IsValid {
 		t.Error("Wordpress article marked as deleted shouldn't fail external validation.")
 	}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/Financial-Times/message-queue-gonsumer/consumer"
+	"github.com/Financial-Times/publish-availability-monitor/content"
+	log "github.com/Sirupsen/logrus"
+)
+
+// contentSource unmarshals the messages published by a CMS into their content,
+// with its type and UUID resolved.
+type contentSource func(ctx context.Context, h *kafkaMessageHandler, msg consumer.Message) (content.Content, error)
+
+// contentSources are the CMSs whose messages are checked, by their system ID,
+// sent in the Origin-System-Id header.
+var contentSources = map[string]contentSource{
+	"http://cmdb.ft.com/systems/methode-web-pub":   unmarshalMethodeContent,
+	"http://cmdb.ft.com/systems/wordpress":         unmarshalWordPressContent,
+	"http://cmdb.ft.com/systems/next-video-editor": unmarshalVideoContent,
+}
+
+// registerContentSources checks the messages of the CMSs configured with a
+// content.SourceConfig, by their system ID. They replace the CMSs known
+// with the same system ID.
+func registerContentSources(sources map[string]content.SourceConfig) {
+	for systemID, conf := range sources {
+		source, err := content.NewGenericSource(conf)
+		if err != nil {
+			log.Errorf("Invalid content source [%v]: [%v]", systemID, err)
+			continue
+		}
+		log.Infof("Checking the content of [%v]", systemID)
+		contentSources[systemID] = func(ctx context.Context, h *kafkaMessageHandler, msg consumer.Message) (content.Content, error) {
+			return source.Unmarshal([]byte(msg.Body))
+		}
+	}
+}
+
+func unmarshalMethodeContent(ctx context.Context, h *kafkaMessageHandler, msg consumer.Message) (content.Content, error) {
+	binaryContent := []byte(msg.Body)
+	var eomFile content.EomFile
+
+	err := json.Unmarshal(binaryContent, &eomFile)
+	if err != nil {
+		return nil, err
+	}
+	xml.Unmarshal([]byte(eomFile.Attributes), &eomFile.Source)
+	eomFile = eomFile.Initialize(binaryContent).(content.EomFile)
+	theType, resolvedUuid, err := h.typeRes.ResolveTypeAndUuid(ctx, eomFile, msg.Headers["X-Request-Id"])
+	if err != nil {
+		return nil, fmt.Errorf("couldn't map kafka message to methode Content while fetching its type and uuid. %v", err)
+	}
+	eomFile.Type = theType
+	eomFile.UUID = resolvedUuid
+	return eomFile, nil
+}
+
+func unmarshalWordPressContent(ctx context.Context, h *kafkaMessageHandler, msg consumer.Message) (content.Content, error) {
+	var wordPressMsg content.WordPressMessage
+	err := json.Unmarshal([]byte(msg.Body), &wordPressMsg)
+	if err != nil {
+		return nil, err
+	}
+	return wordPressMsg.Initialize([]byte(msg.Body)), nil
+}
+
+func unmarshalVideoContent(ctx context.Context, h *kafkaMessageHandler, msg consumer.Message) (content.Content, error) {
+	var video content.Video
+	err := json.Unmarshal([]byte(msg.Body), &video)
+	if err != nil {
+		return nil, err
+	}
+	return video.Initialize([]byte(msg.Body)), nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Financial-Times/message-queue-gonsumer/consumer"
+	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const liveBlogSystemID = "http://cmdb.ft.com/systems/live-blogs"
+
+func TestUnmarshalContent_ConfiguredContentSource(t *testing.T) {
+	defer func(saved map[string]contentSource) { contentSources = saved }(contentSources)
+	contentSources = map[string]contentSource{"http://cmdb.ft.com/systems/wordpress": unmarshalWordPressContent}
+
+	registerContentSources(map[string]content.SourceConfig{
+		liveBlogSystemID:                     {ContentType: "LiveBlog", UUIDPath: "$.uuid"},
+		"http://cmdb.ft.com/systems/invalid": {ContentType: "LiveBlog"},
+	})
+
+	h := kafkaMessageHandler{new(MockTypeResolver)}
+	c, err := h.unmarshalContent(context.Background(), consumer.Message{
+		Headers: map[string]string{"Origin-System-Id": liveBlogSystemID},
+		Body:    `{"uuid": "e28b12f7-9796-3331-b030-05082f0b8157"}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "LiveBlog", c.GetType())
+	assert.Equal(t, "e28b12f7-9796-3331-b030-05082f0b8157", c.GetUUID())
+
+	assert.Contains(t, contentSources, "http://cmdb.ft.com/systems/wordpress", "the known sources should be kept")
+	assert.NotContains(t, contentSources, "http://cmdb.ft.com/systems/invalid")
+}
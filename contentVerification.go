@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/Financial-Times/uuid-utils-go"
+	log "github.com/Sirupsen/logrus"
+)
+
+// The findings of the content available at an endpoint whose fields are not
+// the ones of the published content, see MetricConfig.VerifyFields
+const (
+	findingWrongTitle     = "wrong-title"
+	findingWrongBody      = "wrong-body"
+	findingWrongByline    = "wrong-byline"
+	findingWrongMainImage = "wrong-main-image"
+)
+
+var verificationFindings = map[string]string{
+	content.TitleField:     findingWrongTitle,
+	content.BodyHashField:  findingWrongBody,
+	content.BylineField:    findingWrongByline,
+	content.MainImageField: findingWrongMainImage,
+}
+
+// findings compares the content available at the endpoint with the fields of
+// the published content, when the endpoint is configured to verify them.
+func (c ContentCheck) findings(ctx context.Context, pc *PublishCheck) []string {
+	pm := pc.Metric
+	if pm.isMarkedDeleted || len(pm.expectedFields) == 0 {
+		return nil
+	}
+
+	url := pm.endpoint.String() + pm.UUID
+	resp, err := c.httpCaller.DoCall(ctx, checks.Config{Url: url, Username: pc.username, Password: pc.password, TxId: checks.ConstructPamTxId(pm.tid)})
+	if err != nil {
+		pc.logger().Warnf("Error calling URL: [%v] to verify the content : [%v]", url, err.Error())
+		return nil
+	}
+	defer cleanupResp(resp)
+
+	if resp.StatusCode != 200 {
+		pc.logger().Warnf("Cannot verify the content, status code [%v]", resp.StatusCode)
+		return nil
+	}
+
+	var served map[string]interface{}
+	if err = json.NewDecoder(resp.Body).Decode(&served); err != nil {
+		pc.logger().Warnf("Cannot verify the content. Cannot unmarshal JSON response: [%s]", err.Error())
+		return nil
+	}
+	if publishRef, ok := served["publishReference"].(string); ok && publishRef != pm.tid {
+		pc.logger().Infof("Cannot verify the content, it was published again since by [%v]", publishRef)
+		return nil
+	}
+
+	findings := verifyContent(served, pm.expectedFields)
+	if len(findings) > 0 {
+		pc.logger().Warnf("Content available but wrong: %v", findings)
+	}
+	return findings
+}
+
+// verifyContent returns the findings for the expected fields which the served content does not match.
+func verifyContent(served map[string]interface{}, expected map[string]string) []string {
+	var findings []string
+	for _, field := range content.VerifiableFields {
+		value, found := expected[field]
+		if !found {
+			continue
+		}
+
+		actual := servedField(served, field)
+		matches := actual == value
+		if field == content.MainImageField {
+			// the read APIs serve the image set of the main image, whose ID is its URL
+			imageSetUUID := imageSetUUIDOf(value)
+			matches = imageSetUUID != "" && strings.HasSuffix(actual, imageSetUUID)
+		}
+		if !matches {
+			findings = append(findings, verificationFindings[field])
+		}
+	}
+	return findings
+}
+
+// imageSetUUIDOf returns the UUID of the image set of the image imageUUID, or
+// the empty string when imageUUID is invalid.
+func imageSetUUIDOf(imageUUID string) string {
+	uuid, err := uuidutils.NewUUIDFromString(imageUUID)
+	if err != nil {
+		log.Warnf("Cannot verify the main image, invalid UUID [%v]: [%v]", imageUUID, err.Error())
+		return ""
+	}
+	imageSetUUID, err := uuidDeriver.From(uuid)
+	if err != nil {
+		log.Warnf("Cannot verify the main image, cannot derive the image set UUID of [%v]: [%v]", imageUUID, err.Error())
+		return ""
+	}
+	return imageSetUUID.String()
+}
+
+// servedField returns the value of field in the JSON content served by the read APIs.
+func servedField(served map[string]interface{}, field string) string {
+	switch field {
+	case content.TitleField:
+		title, _ := served["title"].(string)
+		return strings.TrimSpace(title)
+	case content.BylineField:
+		byline, _ := served["byline"].(string)
+		return strings.TrimSpace(byline)
+	case content.BodyHashField:
+		if body, _ := served["bodyXML"].(string); body != "" {
+			return content.BodyHash(body)
+		}
+	case content.MainImageField:
+		if image, ok := served["mainImage"].(map[string]interface{}); ok {
+			id, _ := image["id"].(string)
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentCheckFindsAvailableButWrongContent(t *testing.T) {
+	testResponse := `{"uuid": "1234-1234", "publishReference": "tid_1234", "title": "Markets fell",
+		"byline": "Jane Doe", "bodyXML": "<body><p>Stocks <ft-concept id=\"1\">rose</ft-concept>.</p></body>"}`
+	contentCheck := &ContentCheck{
+		mockHTTPCaller(t, "tid_pam_1234", buildResponse(200, testResponse)),
+	}
+
+	pm := newPublishMetricBuilder().withTID("tid_1234").build()
+	pm.expectedFields = map[string]string{
+		content.TitleField:     "Markets rose",
+		content.BylineField:    "Jane Doe",
+		content.BodyHashField:  content.BodyHash("<p>Stocks rose.</p>"),
+		content.MainImageField: "7baa33ba-eded-11e6-ba01-119a44939bb6",
+	}
+
+	findings := contentCheck.findings(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
+	assert.Equal(t, []string{findingWrongTitle, findingWrongMainImage}, findings)
+}
+
+func TestVerifyContentComparesTheMainImageWithItsImageSet(t *testing.T) {
+	expected := map[string]string{content.MainImageField: "7baa33ba-eded-11e6-ba01-119a44939bb6"}
+	served := func(id string) map[string]interface{} {
+		return map[string]interface{}{"mainImage": map[string]interface{}{"id": id}}
+	}
+
+	assert.Empty(t, verifyContent(served("http://api.ft.com/content/"+imageSetUUIDOf("7baa33ba-eded-11e6-ba01-119a44939bb6")), expected))
+	assert.Equal(t, []string{findingWrongMainImage}, verifyContent(served("http://api.ft.com/content/7baa33ba-eded-11e6-ba01-119a44939bb6"), expected),
+		"the read APIs serve the image set of the main image, not the image")
+	assert.Equal(t, []string{findingWrongMainImage}, verifyContent(served(""), expected))
+}
+
+func TestContentCheckFindsNothingWhenTheContentIsRight(t *testing.T) {
+	testResponse := `{"uuid": "1234-1234", "publishReference": "tid_1234", "title": "Markets rose",
+		"mainImage": {"id": "http://api.ft.com/content/` + imageSetUUIDOf("7baa33ba-eded-11e6-ba01-119a44939bb6") + `"}}`
+	contentCheck := &ContentCheck{
+		mockHTTPCaller(t, "tid_pam_1234", buildResponse(200, testResponse)),
+	}
+
+	pm := newPublishMetricBuilder().withTID("tid_1234").build()
+	pm.expectedFields = map[string]string{
+		content.TitleField:     "Markets rose",
+		content.MainImageField: "7baa33ba-eded-11e6-ba01-119a44939bb6",
+	}
+
+	assert.Empty(t, contentCheck.findings(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil)))
+}
+
+func TestContentCheckDoesNotVerifyLaterPublishes(t *testing.T) {
+	testResponse := `{"uuid": "1234-1234", "publishReference": "tid_1235", "title": "Markets fell"}`
+	contentCheck := &ContentCheck{
+		mockHTTPCaller(t, "tid_pam_1234", buildResponse(200, testResponse)),
+	}
+
+	pm := newPublishMetricBuilder().withTID("tid_1234").build()
+	pm.expectedFields = map[string]string{content.TitleField: "Markets rose"}
+
+	assert.Empty(t, contentCheck.findings(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil)))
+}
+
+func TestContentCheckVerifiesNothingByDefault(t *testing.T) {
+	// the content should not be requested, as the mock has no response
+	contentCheck := &ContentCheck{mockHTTPCaller(t, "")}
+
+	pm := newPublishMetricBuilder().withTID("tid_1234").build()
+
+	assert.Empty(t, contentCheck.findings(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil)))
+}
+
+func TestExpectedFieldsForTheVerifiedFields(t *testing.T) {
+	video := content.Video{ID: "1234-1234", Name: "Markets now"}
+
+	assert.Nil(t, expectedFieldsFor(MetricConfig{Alias: "content"}, video))
+	assert.Equal(t, map[string]string{content.TitleField: "Markets now"},
+		expectedFieldsFor(MetricConfig{Alias: "content", VerifyFields: []string{content.TitleField, content.BylineField}}, video))
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	defaultLagThreshold = 60 * time.Second
+	// how long after the end of its checks the results of a publish are waited for
+	comparisonSlack       = time.Minute
+	defaultMaxDivergences = 100
+
+	// the content was available in other environments, but not in this one
+	divergenceMissing = "missing"
+	// the content was available in this environment much later than in the fastest one
+	divergenceLagging = "lagging"
+)
+
+var environmentDivergences = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "pam_environment_divergences_total",
+	Help: "Number of publishes which were available in other environments, but not in this one, or much later.",
+}, []string{"environment", "alias", "divergence"})
+
+// ComparisonConfig holds the configuration of the comparison of the publishes across environments
+type ComparisonConfig struct {
+	LagThreshold   int `json:"lagThreshold"`   //seconds by which content available later in an environment than in the fastest one diverges, ex. 60
+	MaxDivergences int `json:"maxDivergences"` //divergences kept to be served on /__environment-comparison, ex. 100
+}
+
+// divergence is a publish whose outcome in an environment diverged from the other environments.
+type divergence struct {
+	UUID          string    `json:"uuid"`
+	TransactionID string    `json:"transactionId"`
+	Alias         string    `json:"alias"`
+	Environment   string    `json:"environment"`
+	Divergence    string    `json:"divergence"`
+	Lag           float64   `json:"lag,omitempty"` //seconds after the fastest environment, for lagging content
+	AvailableIn   []string  `json:"availableIn"`
+	PublishDate   time.Time `json:"publishDate"`
+}
+
+// environmentLag summarises how an environment compares with the others.
+type environmentLag struct {
+	Environment string  `json:"environment"`
+	Compared    int     `json:"compared"` //publishes compared with other environments, available in at least one of them
+	Missing     int     `json:"missing"`
+	Lagging     int     `json:"lagging"`
+	MeanLag     float64 `json:"meanLag"` //seconds after the fastest environment, for the publishes available in it
+	MaxLag      float64 `json:"maxLag"`
+	totalLag    float64
+	available   int
+}
+
+type comparisonReport struct {
+	Environments []environmentLag `json:"environments"`
+	Divergences  []divergence     `json:"divergences"`
+}
+
+// environmentResults are the results of the checks of a publish at an endpoint, by environment.
+type environmentResults struct {
+	expected []string
+	results  map[string]PublishMetric
+	timer    *time.Timer
+}
+
+// EnvironmentComparator implements MetricDestination interface to compare the
+// results of the checks of a publish at the same endpoint across the environments.
+// A divergence is logged when the content was available in an environment, but
+// not in another, or much later. The results of a publish are compared once
+// all the environments reported them, or after the checks of the publish ended.
+type EnvironmentComparator struct {
+	sync.Mutex
+	envs           *threadSafeEnvironments
+	lagThreshold   time.Duration
+	graceWindow    time.Duration
+	slack          time.Duration
+	maxDivergences int
+	pending        map[string]*environmentResults
+	lags           map[string]*environmentLag
+	divergences    []divergence
+}
+
+// NewEnvironmentComparator returns an EnvironmentComparator of the results in envs.
+func NewEnvironmentComparator(conf ComparisonConfig, graceWindow int, envs *threadSafeEnvironments) *EnvironmentComparator {
+	c := &EnvironmentComparator{
+		envs:           envs,
+		lagThreshold:   defaultLagThreshold,
+		graceWindow:    time.Duration(graceWindow) * time.Second,
+		slack:          comparisonSlack,
+		maxDivergences: defaultMaxDivergences,
+		pending:        make(map[string]*environmentResults),
+		lags:           make(map[string]*environmentLag),
+	}
+	if conf.LagThreshold > 0 {
+		c.lagThreshold = time.Duration(conf.LagThreshold) * time.Second
+	}
+	if conf.MaxDivergences > 0 {
+		c.maxDivergences = conf.MaxDivergences
+	}
+	return c
+}
+
+// Send adds pm to the results of its publish, and compares them if all the environments reported theirs.
+func (c *EnvironmentComparator) Send(pm PublishMetric) {
+	if pm.findingsReport {
+		// the result of the check was compared when it was sent
+		return
+	}
+	if _, found := c.envs.lookup(pm.platform); !found {
+		// the endpoint was not checked in any environment
+		return
+	}
+
+	key := pm.tid + " " + pm.config.Alias
+	c.Lock()
+	defer c.Unlock()
+	p, found := c.pending[key]
+	if !found {
+		expected := c.envs.names()
+		if len(expected) < 2 {
+			return
+		}
+		p = &environmentResults{expected: expected, results: make(map[string]PublishMetric)}
+		checksEnd := pm.publishDate.Add(time.Duration(pm.threshold)*time.Second + c.graceWindow)
+		p.timer = time.AfterFunc(time.Until(checksEnd)+c.slack, func() {
+			c.Lock()
+			defer c.Unlock()
+			c.compare(key)
+		})
+		c.pending[key] = p
+	}
+	p.results[pm.platform] = pm
+
+	for _, env := range p.expected {
+		if _, reported := p.results[env]; !reported {
+			return
+		}
+	}
+	p.timer.Stop()
+	c.compare(key)
+}
+
+// Flush does nothing: the results which were not compared yet are incomplete,
+// and comparing them would report divergences which did not happen.
+func (c *EnvironmentComparator) Flush() {}
+
+// compare compares the results of the publish at key and forgets them.
+// It must be called with the lock held.
+func (c *EnvironmentComparator) compare(key string) {
+	p, found := c.pending[key]
+	if !found {
+		return
+	}
+	delete(c.pending, key)
+
+	var available, missing []PublishMetric
+	for _, pm := range p.results {
+		switch outcomeOf(pm) {
+		case outcomeOnTime, outcomeLate:
+			available = append(available, pm)
+		case outcomeMissing:
+			missing = append(missing, pm)
+		}
+	}
+	if len(available) == 0 {
+		return
+	}
+	sort.Slice(available, func(i, j int) bool { return available[i].latency < available[j].latency })
+
+	var availableIn []string
+	for _, pm := range available {
+		availableIn = append(availableIn, pm.platform)
+	}
+	fastest := available[0].latency
+	for i, pm := range available {
+		lag := pm.latency - fastest
+		if len(available) > 1 || len(missing) > 0 {
+			c.recordLag(pm.platform, lag)
+		}
+		if i > 0 && lag > c.lagThreshold {
+			c.diverged(pm, divergenceLagging, lag, availableIn)
+		}
+	}
+	for _, pm := range missing {
+		c.lagOf(pm.platform).Compared++
+		c.diverged(pm, divergenceMissing, 0, availableIn)
+	}
+}
+
+func (c *EnvironmentComparator) recordLag(env string, lag time.Duration) {
+	l := c.lagOf(env)
+	l.Compared++
+	l.available++
+	l.totalLag += lag.Seconds()
+	l.MeanLag = l.totalLag / float64(l.available)
+	if lag.Seconds() > l.MaxLag {
+		l.MaxLag = lag.Seconds()
+	}
+}
+
+func (c *EnvironmentComparator) lagOf(env string) *environmentLag {
+	l, found := c.lags[env]
+	if !found {
+		l = &environmentLag{Environment: env}
+		c.lags[env] = l
+	}
+	return l
+}
+
+func (c *EnvironmentComparator) diverged(pm PublishMetric, kind string, lag time.Duration, availableIn []string) {
+	d := divergence{
+		UUID:          pm.UUID,
+		TransactionID: pm.tid,
+		Alias:         pm.config.Alias,
+		Environment:   pm.platform,
+		Divergence:    kind,
+		Lag:           lag.Seconds(),
+		AvailableIn:   availableIn,
+		PublishDate:   pm.publishDate,
+	}
+	switch kind {
+	case divergenceMissing:
+		c.lagOf(pm.platform).Missing++
+	case divergenceLagging:
+		c.lagOf(pm.platform).Lagging++
+	}
+	environmentDivergences.WithLabelValues(pm.platform, pm.config.Alias, kind).Inc()
+
+	c.divergences = append(c.divergences, d)
+	if len(c.divergences) > c.maxDivergences {
+		c.divergences = c.divergences[len(c.divergences)-c.maxDivergences:]
+	}
+	loggerForCheck(pm.config.Alias, pm.UUID, pm.platform, pm.tid).WithFields(log.Fields{
+		"event":       "divergence",
+		"divergence":  kind,
+		"lag":         d.Lag,
+		"availableIn": availableIn,
+	}).Warnf("Publish diverged from the other environments: [%v]", kind)
+}
+
+// report returns the lag of each environment, and the latest divergences first.
+func (c *EnvironmentComparator) report() comparisonReport {
+	c.Lock()
+	defer c.Unlock()
+
+	r := comparisonReport{Environments: []environmentLag{}, Divergences: []divergence{}}
+	for _, l := range c.lags {
+		r.Environments = append(r.Environments, *l)
+	}
+	sort.Slice(r.Environments, func(i, j int) bool { return r.Environments[i].Environment < r.Environments[j].Environment })
+	for i := len(c.divergences) - 1; i >= 0; i-- {
+		r.Divergences = append(r.Divergences, c.divergences[i])
+	}
+	return r
+}
+
+// environmentComparisonHandler serves the report of c as JSON.
+func environmentComparisonHandler(c *EnvironmentComparator) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(c.report()); err != nil {
+			log.WithError(err).Error("Cannot write environment comparison")
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newComparisonTestEnvironments(names ...string) *threadSafeEnvironments {
+	envs := newThreadSafeEnvironments()
+	for _, name := range names {
+		envs.envMap[name] = Environment{name, "http://" + name + ".example.org", "", "", ""}
+	}
+	return envs
+}
+
+func newComparisonTestMetric(env string, latency time.Duration) PublishMetric {
+	pm := newHistoryTestMetric("uuid1", "tid_1", env, "content", time.Now(), latency > 0)
+	pm.latency = latency
+	pm.threshold = 120
+	return pm
+}
+
+func TestEnvironmentComparatorReportsLaggingEnvironments(t *testing.T) {
+	c := NewEnvironmentComparator(ComparisonConfig{LagThreshold: 30}, 180, newComparisonTestEnvironments("env1", "env2", "env3"))
+
+	c.Send(newComparisonTestMetric("env1", 10*time.Second))
+	c.Send(newComparisonTestMetric("env2", 15*time.Second))
+	assert.Empty(t, c.report().Environments, "the results should be compared once all the environments reported them")
+	c.Send(newComparisonTestMetric("env3", 70*time.Second))
+
+	r := c.report()
+	require.Len(t, r.Divergences, 1)
+	assert.Equal(t, "env3", r.Divergences[0].Environment)
+	assert.Equal(t, divergenceLagging, r.Divergences[0].Divergence)
+	assert.Equal(t, 60.0, r.Divergences[0].Lag)
+	assert.Equal(t, []string{"env1", "env2", "env3"}, r.Divergences[0].AvailableIn)
+
+	require.Len(t, r.Environments, 3)
+	assert.Equal(t, environmentLag{Environment: "env1", Compared: 1, available: 1}, r.Environments[0])
+	assert.Equal(t, 5.0, r.Environments[1].MeanLag)
+	assert.Equal(t, 0, r.Environments[1].Lagging)
+	assert.Equal(t, 1, r.Environments[2].Lagging)
+	assert.Equal(t, 60.0, r.Environments[2].MaxLag)
+	assert.Empty(t, c.pending)
+}
+
+func TestEnvironmentComparatorReportsMissingContent(t *testing.T) {
+	c := NewEnvironmentComparator(ComparisonConfig{}, 180, newComparisonTestEnvironments("env1", "env2"))
+
+	c.Send(newComparisonTestMetric("env2", 0))
+	c.Send(newComparisonTestMetric("env1", 20*time.Second))
+
+	r := c.report()
+	require.Len(t, r.Divergences, 1)
+	assert.Equal(t, "env2", r.Divergences[0].Environment)
+	assert.Equal(t, divergenceMissing, r.Divergences[0].Divergence)
+	assert.Equal(t, []string{"env1"}, r.Divergences[0].AvailableIn)
+	require.Len(t, r.Environments, 2)
+	assert.Equal(t, 1, r.Environments[1].Compared)
+	assert.Equal(t, 1, r.Environments[1].Missing)
+}
+
+func TestEnvironmentComparatorIgnoresConsistentOutcomes(t *testing.T) {
+	c := NewEnvironmentComparator(ComparisonConfig{}, 180, newComparisonTestEnvironments("env1", "env2"))
+
+	c.Send(newComparisonTestMetric("env1", 0))
+	c.Send(newComparisonTestMetric("env2", 0))
+	aborted := newComparisonTestMetric("env1", 0)
+	aborted.tid = "tid_2"
+	aborted.outcome = outcomeAborted
+	c.Send(aborted)
+	available := newComparisonTestMetric("env2", 5*time.Second)
+	available.tid = "tid_2"
+	c.Send(available)
+	c.Send(newComparisonTestMetric("none", 0))
+
+	r := c.report()
+	assert.Empty(t, r.Divergences)
+	assert.Empty(t, r.Environments, "the publishes should be compared only when they are available in an environment and checked in another")
+}
+
+func TestEnvironmentComparatorComparesTheResultsReportedAfterTheChecksEnded(t *testing.T) {
+	c := NewEnvironmentComparator(ComparisonConfig{}, 0, newComparisonTestEnvironments("env1", "env2", "removed-env"))
+	c.slack = 10 * time.Millisecond
+
+	missing := newComparisonTestMetric("env1", 0)
+	missing.threshold = 0
+	c.Send(missing)
+	c.Send(newComparisonTestMetric("env2", 5*time.Second))
+
+	assert.Empty(t, c.report().Divergences)
+	deadline := time.Now().Add(time.Second)
+	for len(c.report().Divergences) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	r := c.report()
+	require.Len(t, r.Divergences, 1, "the results should be compared without the environments which did not report theirs")
+	assert.Equal(t, divergenceMissing, r.Divergences[0].Divergence)
+}
+
+func TestEnvironmentComparisonHandler(t *testing.T) {
+	c := NewEnvironmentComparator(ComparisonConfig{MaxDivergences: 1}, 180, newComparisonTestEnvironments("env1", "env2"))
+	for _, tid := range []string{"tid_1", "tid_2"} {
+		missing := newComparisonTestMetric("env1", 0)
+		missing.tid = tid
+		c.Send(missing)
+		available := newComparisonTestMetric("env2", 10*time.Second)
+		available.tid = tid
+		c.Send(available)
+	}
+
+	w := httptest.NewRecorder()
+	environmentComparisonHandler(c)(w, httptest.NewRequest("GET", "/__environment-comparison", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var r comparisonReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &r))
+	require.Len(t, r.Divergences, 1, "only the latest divergences should be kept")
+	assert.Equal(t, "tid_2", r.Divergences[0].TransactionID)
+	require.Len(t, r.Environments, 2)
+	assert.Equal(t, "env1", r.Environments[0].Environment)
+	assert.Equal(t, 2, r.Environments[0].Missing)
+}
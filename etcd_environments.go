@@ -55,6 +55,13 @@ func (tse *threadSafeEnvironments) environment(name string) Environment {
 	return tse.envMap[name]
 }
 
+func (tse *threadSafeEnvironments) lookup(name string) (Environment, bool) {
+	tse.RLock()
+	defer tse.RUnlock()
+	env, found := tse.envMap[name]
+	return env, found
+}
+
 func (tse *threadSafeEnvironments) areReady() bool {
 	tse.RLock()
 	defer tse.RUnlock()
@@ -232,6 +239,9 @@ func watch(etcdKey *string, fn func()) {
 }
 
 func configureEtcdFeeds(envMap map[string]Environment, removedEnvs []string) {
+	subscribedFeedsLock.Lock()
+	defer subscribedFeedsLock.Unlock()
+
 	for _, envName := range removedEnvs {
 		feeds, found := subscribedFeeds[envName]
 		if found {
@@ -267,9 +277,12 @@ func configureEtcdFeeds(envMap map[string]Environment, removedEnvs []string) {
 					continue
 				}
 
-				interval := appConfig.Threshold / metric.Granularity
+				threshold := appConfig.maxThresholdAt(metric)
+				interval := checkIntervalFor(metric, threshold)
+				expiry := threshold + appConfig.GraceWindow
 
-				if f := feeds.NewNotificationsFeed(metric.Alias, *endpointUrl, appConfig.Threshold, interval, env.Username, env.Password, metric.ApiKey); f != nil {
+				if f := feeds.NewFeed(metric.feedType(), metric.Alias, *endpointUrl, expiry, interval, env.Username, env.Password, metric.ApiKey); f != nil {
+					configureFeed(f, metric)
 					subscribedFeeds[env.Name] = append(envFeeds, f)
 					f.Start()
 				}
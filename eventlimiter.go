@@ -10,7 +10,13 @@ type EventLimiter struct {
 }
 
 func NewEventLimiter(f func()) *EventLimiter {
-	ticker := time.NewTicker(60 * time.Second)
+	return NewEventLimiterWithInterval(60*time.Second, f)
+}
+
+// NewEventLimiterWithInterval returns an EventLimiter which calls f at most
+// once per interval, if it was triggered since the last call.
+func NewEventLimiterWithInterval(interval time.Duration, f func()) *EventLimiter {
+	ticker := time.NewTicker(interval)
 	trigger := make(chan bool, 1)
 	wasTriggered := make(chan bool, 1)
 	timePassed := make(chan bool, 1)
@@ -0,0 +1,63 @@
+package feeds
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ReconnectPolicy defines how long a feed waits before reconnecting: the delay
+// starts at Initial and is multiplied by Multiplier after every failed attempt,
+// up to Max. A random part of up to Jitter times the delay is subtracted, so
+// that the feeds of all the environments do not reconnect at the same time.
+type ReconnectPolicy struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64 //between 0 and 1
+}
+
+// DefaultReconnectPolicy waits between 250ms and 500ms before the first
+// reconnection, and up to one minute when the feed stays unreachable.
+var DefaultReconnectPolicy = ReconnectPolicy{
+	Initial:    500 * time.Millisecond,
+	Max:        time.Minute,
+	Multiplier: 2,
+	Jitter:     0.5,
+}
+
+// backoff computes the reconnection delays of a ReconnectPolicy.
+type backoff struct {
+	policy  ReconnectPolicy
+	lock    *sync.Mutex
+	attempt int
+}
+
+func newBackoff(policy ReconnectPolicy) *backoff {
+	return &backoff{policy: policy, lock: &sync.Mutex{}}
+}
+
+// Next returns the delay before the next reconnection attempt.
+func (b *backoff) Next() time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delay := float64(b.policy.Initial)
+	for i := 0; i < b.attempt && delay < float64(b.policy.Max); i++ {
+		delay *= b.policy.Multiplier
+	}
+	if delay > float64(b.policy.Max) {
+		delay = float64(b.policy.Max)
+	}
+	b.attempt++
+
+	return time.Duration(delay - delay*b.policy.Jitter*rand.Float64())
+}
+
+// Reset starts the delays from Initial again, after a successful connection.
+func (b *backoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.attempt = 0
+}
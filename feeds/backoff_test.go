@@ -0,0 +1,31 @@
+package feeds
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffGrowsExponentiallyUpToTheMax(t *testing.T) {
+	b := newBackoff(ReconnectPolicy{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2})
+
+	assert.Equal(t, time.Second, b.Next())
+	assert.Equal(t, 2*time.Second, b.Next())
+	assert.Equal(t, 4*time.Second, b.Next())
+	assert.Equal(t, 5*time.Second, b.Next())
+	assert.Equal(t, 5*time.Second, b.Next())
+
+	b.Reset()
+	assert.Equal(t, time.Second, b.Next())
+}
+
+func TestBackoffJitter(t *testing.T) {
+	b := newBackoff(ReconnectPolicy{Initial: time.Second, Max: time.Minute, Multiplier: 2, Jitter: 0.5})
+
+	for i := 0; i < 100; i++ {
+		b.Reset()
+		delay := b.Next()
+		assert.True(t, delay > 500*time.Millisecond && delay <= time.Second, "delay [%v] should be between 500ms and 1s", delay)
+	}
+}
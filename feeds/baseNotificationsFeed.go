@@ -19,7 +19,7 @@ type baseNotificationsFeed struct {
 	username          string
 	password          string
 	expiry            int
-	notifications     map[string][]*Notification
+	notifications     *notificationsBuffer
 	notificationsLock *sync.RWMutex
 }
 
@@ -53,47 +53,56 @@ func (f *baseNotificationsFeed) SetHttpCaller(httpCaller checks.HttpCaller) {
 }
 
 func (f *baseNotificationsFeed) purgeObsoleteNotifications() {
-	earliest := time.Now().Add(time.Duration(-f.expiry) * time.Second).Format(time.RFC3339)
-	empty := make([]string, 0)
+	earliest := time.Now().Add(time.Duration(-f.expiry) * time.Second)
 
 	f.notificationsLock.Lock()
 	defer f.notificationsLock.Unlock()
 
-	for u, n := range f.notifications {
-		earliestIndex := 0
-		for _, e := range n {
-			if strings.Compare(e.LastModified, earliest) >= 0 {
-				break
-			} else {
-				earliestIndex++
-			}
-		}
-		f.notifications[u] = n[earliestIndex:]
-
-		if len(f.notifications[u]) == 0 {
-			empty = append(empty, u)
-		}
-	}
+	f.notifications.purge(earliest)
+}
+
+// storeNotifications buffers the notifications received by the feed, keeping
+// the duplicates so they can be reported.
+func (f *baseNotificationsFeed) storeNotifications(notifications []Notification) {
+	f.notificationsLock.Lock()
+	defer f.notificationsLock.Unlock()
 
-	for _, u := range empty {
-		delete(f.notifications, u)
+	for _, n := range notifications {
+		f.notifications.add(n)
 	}
 }
 
 func (f *baseNotificationsFeed) NotificationsFor(uuid string) []*Notification {
-	var history []*Notification
-	var found bool
-
 	f.notificationsLock.RLock()
 	defer f.notificationsLock.RUnlock()
 
-	if history, found = f.notifications[uuid]; !found {
-		history = make([]*Notification, 0)
-	}
-
-	return history
+	return f.notifications.notificationsFor(uuid)
 }
 
 func (f *baseNotificationsFeed) FeedURL() string {
 	return f.baseUrl
 }
+
+// BufferedUUIDs returns the number of UUIDs with notifications in the feed's buffer.
+func (f *baseNotificationsFeed) BufferedUUIDs() int {
+	f.notificationsLock.RLock()
+	defer f.notificationsLock.RUnlock()
+
+	return f.notifications.uuids()
+}
+
+// SetBufferLimits bounds the number of UUIDs buffered by the feed, and of
+// notifications for each of them. Limits which are not positive are left unchanged.
+func (f *baseNotificationsFeed) SetBufferLimits(maxUUIDs int, maxNotificationsPerUUID int) {
+	f.notificationsLock.Lock()
+	defer f.notificationsLock.Unlock()
+
+	f.notifications.setLimits(maxUUIDs, maxNotificationsPerUUID)
+}
+
+func (f *baseNotificationsFeed) deleteBufferMetrics() {
+	f.notificationsLock.Lock()
+	defer f.notificationsLock.Unlock()
+
+	f.notifications.deleteMetrics()
+}
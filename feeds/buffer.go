@@ -0,0 +1,182 @@
+package feeds
+
+import (
+	"container/list"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	DefaultMaxBufferedUUIDs        = 50000
+	DefaultMaxNotificationsPerUUID = 20
+)
+
+var (
+	feedBufferedUUIDs = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pam_feed_buffered_uuids",
+		Help: "Number of UUIDs with notifications buffered by a notifications feed.",
+	}, []string{"feed", "url"})
+	feedBufferedNotifications = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pam_feed_buffered_notifications",
+		Help: "Number of notifications buffered by a notifications feed.",
+	}, []string{"feed", "url"})
+	feedBufferEvictions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pam_feed_buffer_evictions_total",
+		Help: "Number of notifications evicted from a full notifications feed buffer before they expired.",
+	}, []string{"feed", "url"})
+)
+
+// bufferedNotification is a notification with its parsed lastModified date.
+type bufferedNotification struct {
+	notification *Notification
+	lastModified time.Time
+}
+
+// bufferEntry holds the notifications of a UUID, the oldest first.
+type bufferEntry struct {
+	uuid          string
+	notifications []bufferedNotification
+}
+
+// notificationsBuffer keeps the notifications of a feed by UUID, until they
+// expire or the buffer is full. When it is full, the notifications of the
+// least recently notified UUID are evicted first.
+// It is not safe for concurrent use, feeds guard it with their notificationsLock.
+type notificationsBuffer struct {
+	feedName                string
+	feedURL                 string
+	maxUUIDs                int
+	maxNotificationsPerUUID int
+	entries                 map[string]*list.Element //of *bufferEntry
+	lru                     *list.List               //the most recently notified UUID at the front
+	size                    int
+}
+
+func newNotificationsBuffer(feedName string, feedURL string) *notificationsBuffer {
+	return &notificationsBuffer{
+		feedName:                feedName,
+		feedURL:                 feedURL,
+		maxUUIDs:                DefaultMaxBufferedUUIDs,
+		maxNotificationsPerUUID: DefaultMaxNotificationsPerUUID,
+		entries:                 make(map[string]*list.Element),
+		lru:                     list.New(),
+	}
+}
+
+// setLimits bounds the number of buffered UUIDs, and of notifications for each
+// of them. Limits which are not positive are left unchanged.
+func (b *notificationsBuffer) setLimits(maxUUIDs int, maxNotificationsPerUUID int) {
+	if maxUUIDs > 0 {
+		b.maxUUIDs = maxUUIDs
+	}
+	if maxNotificationsPerUUID > 0 {
+		b.maxNotificationsPerUUID = maxNotificationsPerUUID
+	}
+	for b.lru.Len() > b.maxUUIDs {
+		b.evict(b.lru.Back())
+	}
+	for _, e := range b.entries {
+		b.truncate(e.Value.(*bufferEntry))
+	}
+	b.updateMetrics()
+}
+
+// add buffers n, even when the same notification is already buffered, so
+// the duplicates can be reported.
+func (b *notificationsBuffer) add(n Notification) {
+	uuid := parseUuidFromUrl(n.ID)
+	e, found := b.entries[uuid]
+	if found {
+		b.lru.MoveToFront(e)
+	} else {
+		e = b.lru.PushFront(&bufferEntry{uuid: uuid})
+		b.entries[uuid] = e
+	}
+
+	entry := e.Value.(*bufferEntry)
+	entry.notifications = append(entry.notifications, bufferedNotification{&n, parseLastModified(n)})
+	b.size++
+
+	b.truncate(entry)
+	for b.lru.Len() > b.maxUUIDs {
+		b.evict(b.lru.Back())
+	}
+	b.updateMetrics()
+}
+
+// notificationsFor returns the notifications buffered for uuid, the oldest first.
+func (b *notificationsBuffer) notificationsFor(uuid string) []*Notification {
+	history := make([]*Notification, 0)
+	if e, found := b.entries[uuid]; found {
+		for _, buffered := range e.Value.(*bufferEntry).notifications {
+			history = append(history, buffered.notification)
+		}
+	}
+	return history
+}
+
+// purge removes the notifications last modified before earliest.
+func (b *notificationsBuffer) purge(earliest time.Time) {
+	for uuid, e := range b.entries {
+		entry := e.Value.(*bufferEntry)
+		var kept []bufferedNotification
+		for _, buffered := range entry.notifications {
+			if !buffered.lastModified.Before(earliest) {
+				kept = append(kept, buffered)
+			}
+		}
+		b.size -= len(entry.notifications) - len(kept)
+		entry.notifications = kept
+
+		if len(kept) == 0 {
+			b.lru.Remove(e)
+			delete(b.entries, uuid)
+		}
+	}
+	b.updateMetrics()
+}
+
+func (b *notificationsBuffer) uuids() int {
+	return len(b.entries)
+}
+
+func (b *notificationsBuffer) truncate(entry *bufferEntry) {
+	if excess := len(entry.notifications) - b.maxNotificationsPerUUID; excess > 0 {
+		entry.notifications = entry.notifications[excess:]
+		b.size -= excess
+		feedBufferEvictions.WithLabelValues(b.feedName, b.feedURL).Add(float64(excess))
+	}
+}
+
+func (b *notificationsBuffer) evict(e *list.Element) {
+	entry := e.Value.(*bufferEntry)
+	log.Debugf("Notifications buffer of [%s] is full, evicting the notifications of [%s]", b.feedURL, entry.uuid)
+	b.lru.Remove(e)
+	delete(b.entries, entry.uuid)
+	b.size -= len(entry.notifications)
+	feedBufferEvictions.WithLabelValues(b.feedName, b.feedURL).Add(float64(len(entry.notifications)))
+}
+
+func (b *notificationsBuffer) updateMetrics() {
+	feedBufferedUUIDs.WithLabelValues(b.feedName, b.feedURL).Set(float64(len(b.entries)))
+	feedBufferedNotifications.WithLabelValues(b.feedName, b.feedURL).Set(float64(b.size))
+}
+
+func (b *notificationsBuffer) deleteMetrics() {
+	feedBufferedUUIDs.DeleteLabelValues(b.feedName, b.feedURL)
+	feedBufferedNotifications.DeleteLabelValues(b.feedName, b.feedURL)
+}
+
+// parseLastModified returns the lastModified date of n, or the current time when
+// it cannot be parsed, so the notification still expires.
+func parseLastModified(n Notification) time.Time {
+	lastModified, err := time.Parse(time.RFC3339Nano, n.LastModified)
+	if err != nil {
+		log.Warnf("Cannot parse lastModified [%s] of notification [%s] with publishReference [%s]", n.LastModified, n.ID, n.PublishReference)
+		return time.Now()
+	}
+	return lastModified
+}
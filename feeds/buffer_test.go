@@ -0,0 +1,81 @@
+package feeds
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferEvictsTheLeastRecentlyNotifiedUUIDs(t *testing.T) {
+	b := newNotificationsBuffer("test-evictions", "http://www.example.org/uuids")
+	b.setLimits(2, 0)
+	evictions := feedBufferEvictions.WithLabelValues("test-evictions", "http://www.example.org/uuids")
+	now := time.Now()
+
+	b.add(testNotification("uuid1", "tid_1", now))
+	b.add(testNotification("uuid2", "tid_2", now))
+	b.add(testNotification("uuid1", "tid_3", now))
+	b.add(testNotification("uuid3", "tid_4", now))
+
+	assert.Equal(t, 2, b.uuids())
+	assert.Len(t, b.notificationsFor("uuid1"), 2)
+	assert.Empty(t, b.notificationsFor("uuid2"), "the least recently notified UUID should be evicted")
+	assert.Len(t, b.notificationsFor("uuid3"), 1)
+	assert.Equal(t, float64(1), testutil.ToFloat64(evictions))
+	assert.Equal(t, float64(2), testutil.ToFloat64(feedBufferedUUIDs.WithLabelValues("test-evictions", "http://www.example.org/uuids")))
+	assert.Equal(t, float64(3), testutil.ToFloat64(feedBufferedNotifications.WithLabelValues("test-evictions", "http://www.example.org/uuids")))
+}
+
+func TestBufferKeepsTheLatestNotificationsOfAUUID(t *testing.T) {
+	b := newNotificationsBuffer("test-evictions", "http://www.example.org/notifications")
+	b.setLimits(0, 2)
+	now := time.Now()
+
+	b.add(testNotification("uuid1", "tid_1", now))
+	b.add(testNotification("uuid1", "tid_2", now))
+	b.add(testNotification("uuid1", "tid_3", now))
+
+	history := b.notificationsFor("uuid1")
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, "tid_2", history[0].PublishReference)
+		assert.Equal(t, "tid_3", history[1].PublishReference)
+	}
+	assert.Equal(t, DefaultMaxBufferedUUIDs, b.maxUUIDs, "limits which are not set should not change")
+	assert.Equal(t, float64(1), testutil.ToFloat64(feedBufferEvictions.WithLabelValues("test-evictions", "http://www.example.org/notifications")))
+}
+
+func TestBufferPurgesByParsedLastModified(t *testing.T) {
+	b := newNotificationsBuffer("test-purge", "http://www.example.org/")
+	earliest := time.Date(2016, 10, 28, 14, 0, 0, 0, time.UTC)
+
+	b.add(Notification{ID: "http://www.ft.com/thing/uuid1", PublishReference: "tid_1", LastModified: "2016-10-28T13:59:59.999Z"})
+	// the same instant in another time zone, which is after earliest when compared as strings
+	b.add(Notification{ID: "http://www.ft.com/thing/uuid2", PublishReference: "tid_2", LastModified: "2016-10-28T15:59:00+02:00"})
+	b.add(Notification{ID: "http://www.ft.com/thing/uuid3", PublishReference: "tid_3", LastModified: "2016-10-28T14:00:00.000Z"})
+	b.add(Notification{ID: "http://www.ft.com/thing/uuid4", PublishReference: "tid_4", LastModified: "not a date"})
+
+	b.purge(earliest)
+
+	assert.Empty(t, b.notificationsFor("uuid1"))
+	assert.Empty(t, b.notificationsFor("uuid2"))
+	assert.Len(t, b.notificationsFor("uuid3"), 1)
+	assert.Len(t, b.notificationsFor("uuid4"), 1, "unparseable notifications should expire from the time they were received")
+	assert.Equal(t, 2, b.uuids())
+	assert.Equal(t, float64(2), testutil.ToFloat64(feedBufferedNotifications.WithLabelValues("test-purge", "http://www.example.org/")))
+}
+
+func testNotification(uuid string, publishRef string, lastModified time.Time) Notification {
+	return Notification{ID: "http://www.ft.com/thing/" + uuid, PublishReference: publishRef, LastModified: lastModified.Format(time.RFC3339)}
+}
+
+func TestBufferKeepsDuplicates(t *testing.T) {
+	b := newNotificationsBuffer("test-duplicates", "http://www.example.org/")
+	n := testNotification("uuid1", "tid_1", time.Now())
+
+	b.add(n)
+	b.add(n)
+
+	assert.Len(t, b.notificationsFor("uuid1"), 2, "duplicated notifications should be reported by the checks")
+}
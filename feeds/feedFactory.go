@@ -9,14 +9,74 @@ import (
 	log "github.com/Sirupsen/logrus"
 )
 
+// FeedFactory builds a feed of a registered type, polling or consuming baseUrl.
+// expiry is how long notifications are kept, and interval how often a pull feed
+// is polled, both in seconds.
+type FeedFactory func(name string, baseUrl url.URL, expiry int, interval int, username string, password string, apiKey string) Feed
+
+var (
+	feedFactories     = make(map[string]FeedFactory)
+	feedFactoriesLock = &sync.RWMutex{}
+)
+
+func init() {
+	RegisterFeedType(NotificationsPull, func(name string, baseUrl url.URL, expiry int, interval int, username string, password string, apiKey string) Feed {
+		return newNotificationsPullFeed(name, baseUrl, expiry, interval, username, password)
+	})
+	RegisterFeedType(NotificationsPush, func(name string, baseUrl url.URL, expiry int, interval int, username string, password string, apiKey string) Feed {
+		return newNotificationsPushFeed(name, baseUrl, expiry, interval, username, password, apiKey)
+	})
+}
+
+// RegisterFeedType makes NewFeed build the feeds of feedType with factory.
+// Feed implementations register themselves in an init function, so they can be
+// selected by the feedType of an endpoint's configuration.
+func RegisterFeedType(feedType string, factory FeedFactory) {
+	feedFactoriesLock.Lock()
+	defer feedFactoriesLock.Unlock()
+
+	feedFactories[feedType] = factory
+}
+
+// IsRegisteredFeedType tells whether NewFeed can build feeds of feedType.
+func IsRegisteredFeedType(feedType string) bool {
+	feedFactoriesLock.RLock()
+	defer feedFactoriesLock.RUnlock()
+
+	_, found := feedFactories[feedType]
+	return found
+}
+
+// NewFeed builds a feed of feedType named name, or returns nil if no such feed
+// type is registered.
+func NewFeed(feedType string, name string, baseUrl url.URL, expiry int, interval int, username string, password string, apiKey string) Feed {
+	feedFactoriesLock.RLock()
+	factory, found := feedFactories[feedType]
+	feedFactoriesLock.RUnlock()
+
+	if !found {
+		return nil
+	}
+	return factory(name, baseUrl, expiry, interval, username, password, apiKey)
+}
+
+// NewNotificationsFeed builds the feed of the endpoint with the alias name, for
+// the endpoints configured without a feed type. It returns nil when the name is
+// not one of a notifications feed.
 func NewNotificationsFeed(name string, baseUrl url.URL, expiry int, interval int, username string, password string, apiKey string) Feed {
+	return NewFeed(FeedTypeOf(name), name, baseUrl, expiry, interval, username, password, apiKey)
+}
+
+// FeedTypeOf returns the type of the notifications feeds known by their name,
+// or an empty string if name is not the name of a notifications feed.
+func FeedTypeOf(name string) string {
 	if isNotificationsPullFeed(name) {
-		return newNotificationsPullFeed(name, baseUrl, expiry, interval, username, password)
+		return NotificationsPull
 	} else if isNotificationsPushFeed(name) {
-		return newNotificationsPushFeed(name, baseUrl, expiry, interval, username, password, apiKey)
+		return NotificationsPush
 	}
 
-	return nil
+	return ""
 }
 
 func isNotificationsPullFeed(feedName string) bool {
@@ -44,34 +104,44 @@ func newNotificationsPullFeed(name string, baseUrl url.URL, expiry int, interval
 			username,
 			password,
 			expiry + 2*interval,
-			make(map[string][]*Notification),
+			newNotificationsBuffer(name, feedUrl),
 			&sync.RWMutex{},
 		},
 		baseUrl.String(),
 		bootstrapValues.Encode(),
 		&sync.Mutex{},
+		"",
+		0,
 		interval,
 		nil,
 		nil,
+		time.Now(),
+		nil,
+		&sync.RWMutex{},
 	}
 }
 
 func newNotificationsPushFeed(name string, baseUrl url.URL, expiry int, interval int, username string, password string, apiKey string) *NotificationsPushFeed {
 	log.Infof("constructing NotificationsPushFeed, bootstrapUrl = [%s]", baseUrl.String())
-	return &NotificationsPushFeed{
-		baseNotificationsFeed{
+	f := &NotificationsPushFeed{
+		baseNotificationsFeed: baseNotificationsFeed{
 			name,
 			nil,
 			baseUrl.String(),
 			username,
 			password,
 			expiry + 2*interval,
-			make(map[string][]*Notification),
+			newNotificationsBuffer(name, baseUrl.String()),
 			&sync.RWMutex{},
 		},
-		true,
-		&sync.RWMutex{},
-		false,
-		apiKey,
+		stopFeed:          true,
+		stopFeedLock:      &sync.RWMutex{},
+		apiKey:            apiKey,
+		stateLock:         &sync.RWMutex{},
+		heartbeatTimeout:  defaultHeartbeatTimeout,
+		reconnect:         newBackoff(DefaultReconnectPolicy),
+		disconnectedSince: time.Now(),
 	}
+	pushFeedDisconnectedSince.WithLabelValues(name, f.baseUrl).Set(float64(f.disconnectedSince.Unix()))
+	return f
 }
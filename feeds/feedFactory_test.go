@@ -29,3 +29,47 @@ func TestNewPushFeed(t *testing.T) {
 	assert.Equal(t, "expectedUser", npf.username)
 	assert.Equal(t, "expectedPwd", npf.password)
 }
+
+func TestNewFeedOfExplicitType(t *testing.T) {
+	baseUrl, _ := url.Parse("http://www.example.org/")
+
+	actual := NewFeed(NotificationsPull, "page-notifications", *baseUrl, 10, 10, "", "", "")
+	assert.IsType(t, (*NotificationsPullFeed)(nil), actual, "expected a NotificationsPullFeed")
+	assert.Equal(t, "page-notifications", actual.FeedName())
+
+	assert.Nil(t, NewFeed("Unknown", "page-notifications", *baseUrl, 10, 10, "", "", ""))
+	assert.Nil(t, NewNotificationsFeed("content", *baseUrl, 10, 10, "", "", ""), "endpoints which are not feeds should have no feed")
+}
+
+type testFeed struct {
+	NotificationsPullFeed
+	apiKey string
+}
+
+func (f *testFeed) FeedType() string {
+	return "Test"
+}
+
+func TestRegisteredFeedTypesAreBuilt(t *testing.T) {
+	RegisterFeedType("Test", func(name string, baseUrl url.URL, expiry int, interval int, username string, password string, apiKey string) Feed {
+		return &testFeed{apiKey: apiKey}
+	})
+	defer func() {
+		feedFactoriesLock.Lock()
+		delete(feedFactories, "Test")
+		feedFactoriesLock.Unlock()
+	}()
+	baseUrl, _ := url.Parse("http://www.example.org/")
+
+	assert.True(t, IsRegisteredFeedType("Test"))
+	actual := NewFeed("Test", "annotations", *baseUrl, 10, 10, "", "", "expectedApiKey")
+	assert.IsType(t, (*testFeed)(nil), actual, "expected a testFeed")
+	assert.Equal(t, "expectedApiKey", actual.(*testFeed).apiKey)
+}
+
+func TestFeedTypeOf(t *testing.T) {
+	assert.Equal(t, NotificationsPull, FeedTypeOf("notifications"))
+	assert.Equal(t, NotificationsPull, FeedTypeOf("list-notifications"))
+	assert.Equal(t, NotificationsPush, FeedTypeOf("list-notifications-push"))
+	assert.Equal(t, "", FeedTypeOf("content"))
+}
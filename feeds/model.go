@@ -1,7 +1,16 @@
 package feeds
 
-// ignore unused fields (e.g. type, apiUrl)
+import "time"
+
+const (
+	// the types of notifications
+	UpdateNotification = "http://www.ft.com/thing/ThingChangeType/UPDATE"
+	DeleteNotification = "http://www.ft.com/thing/ThingChangeType/DELETE"
+)
+
+// ignore unused fields (e.g. apiUrl)
 type Notification struct {
+	Type             string
 	PublishReference string
 	LastModified     string
 	ID               string
@@ -21,3 +30,29 @@ type Feed interface {
 	SetCredentials(username string, password string)
 	NotificationsFor(uuid string) []*Notification
 }
+
+// BufferedFeed is implemented by the feeds which buffer the notifications
+// they receive until they expire or the buffer is full.
+type BufferedFeed interface {
+	BufferedUUIDs() int
+	SetBufferLimits(maxUUIDs int, maxNotificationsPerUUID int)
+}
+
+// Gap is a period in which a feed may have missed notifications.
+type Gap struct {
+	From time.Time
+	To   time.Time
+}
+
+// GapReporter is implemented by the feeds which can tell when they may have
+// missed notifications, so the checks relying on them can be inconclusive
+// rather than failed.
+type GapReporter interface {
+	GapsSince(t time.Time) []Gap
+}
+
+// ConnectivityChecker is implemented by the feeds which consume their
+// notifications from a service whose reachability can be checked.
+type ConnectivityChecker interface {
+	ConnectivityCheck() (string, error)
+}
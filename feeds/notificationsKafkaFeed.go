@@ -0,0 +1,177 @@
+package feeds
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/message-queue-gonsumer/consumer"
+	log "github.com/Sirupsen/logrus"
+)
+
+const NotificationsKafka = "Notifications-Kafka"
+
+func init() {
+	RegisterFeedType(NotificationsKafka, func(name string, baseUrl url.URL, expiry int, interval int, username string, password string, apiKey string) Feed {
+		return newNotificationsKafkaFeed(name, baseUrl, expiry, interval, username, password)
+	})
+}
+
+// NotificationsKafkaFeed consumes the notifications from the Kafka topic they
+// are sent to, through the kafka-rest-proxy at its URL, so they can be checked
+// at the messaging layer rather than in the notifications APIs.
+type NotificationsKafkaFeed struct {
+	baseNotificationsFeed
+	queueConfig  consumer.QueueConfig
+	defaultGroup string
+	consumer     consumer.MessageConsumer //nil while the feed is stopped
+	consumerLock *sync.RWMutex
+	consumerWg   *sync.WaitGroup
+	newConsumer  func(conf consumer.QueueConfig, handler func(m consumer.Message)) consumer.MessageConsumer
+}
+
+func newNotificationsKafkaFeed(name string, baseUrl url.URL, expiry int, interval int, username string, password string) *NotificationsKafkaFeed {
+	log.Infof("constructing NotificationsKafkaFeed, proxy = [%s]", baseUrl.String())
+	return &NotificationsKafkaFeed{
+		baseNotificationsFeed: baseNotificationsFeed{
+			feedName:          name,
+			baseUrl:           baseUrl.String(),
+			username:          username,
+			password:          password,
+			expiry:            expiry + 2*interval,
+			notifications:     newNotificationsBuffer(name, baseUrl.String()),
+			notificationsLock: &sync.RWMutex{},
+		},
+		// the environments have a consumer group each, so that they all receive every notification
+		defaultGroup: "PublishAvailabilityMonitor-" + name + "-" + baseUrl.Host,
+		consumerLock: &sync.RWMutex{},
+		consumerWg:   &sync.WaitGroup{},
+		newConsumer: func(conf consumer.QueueConfig, handler func(m consumer.Message)) consumer.MessageConsumer {
+			return consumer.NewConsumer(conf, handler, &http.Client{Timeout: 60 * time.Second})
+		},
+	}
+}
+
+// SetQueueConfig sets the topic, consumer group and other settings of the
+// consumer, which take effect when the feed is started. The proxy addresses
+// default to the feed URL.
+func (f *NotificationsKafkaFeed) SetQueueConfig(conf consumer.QueueConfig) {
+	f.queueConfig = conf
+}
+
+func (f *NotificationsKafkaFeed) Start() {
+	conf := f.consumerConfig()
+	log.Infof("starting notifications Kafka feed from topic [%v] at %v, group [%v]", conf.Topic, conf.Addrs, conf.Group)
+
+	c := f.newConsumer(conf, f.handleMessage)
+	f.consumerLock.Lock()
+	f.consumer = c
+	f.consumerLock.Unlock()
+
+	f.consumerWg.Add(1)
+	go func() {
+		defer f.consumerWg.Done()
+		c.Start()
+	}()
+}
+
+func (f *NotificationsKafkaFeed) Stop() {
+	log.Infof("shutting down notifications Kafka feed for %s", f.baseUrl)
+	f.consumerLock.Lock()
+	c := f.consumer
+	f.consumer = nil
+	f.consumerLock.Unlock()
+
+	if c != nil {
+		c.Stop()
+		f.consumerWg.Wait()
+	}
+	f.deleteBufferMetrics()
+}
+
+func (f *NotificationsKafkaFeed) FeedType() string {
+	return NotificationsKafka
+}
+
+// ConnectivityCheck tells whether the kafka-rest-proxy of the feed can be reached.
+func (f *NotificationsKafkaFeed) ConnectivityCheck() (string, error) {
+	f.consumerLock.RLock()
+	c := f.consumer
+	f.consumerLock.RUnlock()
+
+	if c == nil {
+		return "The feed is not started", nil
+	}
+	return c.ConnectivityCheck()
+}
+
+// IsConsuming tells whether the feed is started and its kafka-rest-proxy can be reached.
+func (f *NotificationsKafkaFeed) IsConsuming() bool {
+	f.consumerLock.RLock()
+	c := f.consumer
+	f.consumerLock.RUnlock()
+
+	if c == nil {
+		return false
+	}
+	_, err := c.ConnectivityCheck()
+	return err == nil
+}
+
+// consumerConfig completes the configured QueueConfig with the settings of the feed.
+func (f *NotificationsKafkaFeed) consumerConfig() consumer.QueueConfig {
+	conf := f.queueConfig
+	if len(conf.Addrs) == 0 {
+		conf.Addrs = []string{f.baseUrl}
+	}
+	if conf.Group == "" {
+		// the group outlives the restarts of the monitor, which resumes from the last committed offset
+		conf.Group = f.defaultGroup
+	}
+	if conf.AuthorizationKey == "" && f.username != "" {
+		conf.AuthorizationKey = "Basic " + base64.StdEncoding.EncodeToString([]byte(f.username+":"+f.password))
+	}
+	return conf
+}
+
+// handleMessage stores the notifications of msg, whose body is a notification
+// or a list of notifications. The transaction ID and the time of the message
+// are used for the notifications which do not have them.
+func (f *NotificationsKafkaFeed) handleMessage(msg consumer.Message) {
+	tid := msg.Headers["X-Request-Id"]
+	f.purgeObsoleteNotifications()
+
+	var notifications []Notification
+	var err error
+	if body := strings.TrimSpace(msg.Body); strings.HasPrefix(body, "[") {
+		err = json.Unmarshal([]byte(body), &notifications)
+	} else {
+		var n Notification
+		err = json.Unmarshal([]byte(body), &n)
+		notifications = []Notification{n}
+	}
+	if err != nil {
+		log.WithField("transaction_id", tid).Errorf("Cannot unmarshal notifications message from [%s]: [%v]", f.baseUrl, err)
+		return
+	}
+
+	var valid []Notification
+	for _, n := range notifications {
+		if n.ID == "" {
+			log.WithField("transaction_id", tid).Warnf("Ignoring notification without ID from [%s]", f.baseUrl)
+			continue
+		}
+		if n.PublishReference == "" {
+			n.PublishReference = tid
+		}
+		if n.LastModified == "" {
+			n.LastModified = msg.Headers["Message-Timestamp"]
+		}
+		valid = append(valid, n)
+	}
+	f.storeNotifications(valid)
+}
@@ -0,0 +1,129 @@
+package feeds
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/message-queue-gonsumer/consumer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConsumer struct {
+	conf            consumer.QueueConfig
+	handler         func(m consumer.Message)
+	stop            chan struct{}
+	connectivityErr error
+}
+
+func (c *testConsumer) Start() { <-c.stop }
+func (c *testConsumer) Stop()  { close(c.stop) }
+func (c *testConsumer) ConnectivityCheck() (string, error) {
+	if c.connectivityErr != nil {
+		return "", c.connectivityErr
+	}
+	return "ok", nil
+}
+
+func newTestKafkaFeed(t *testing.T, username string, password string) (*NotificationsKafkaFeed, *testConsumer) {
+	return newTestKafkaFeedAt(t, "http://www.example.org/__kafka-rest-proxy", username, password)
+}
+
+func newTestKafkaFeedAt(t *testing.T, proxyUrl string, username string, password string) (*NotificationsKafkaFeed, *testConsumer) {
+	baseUrl, _ := url.Parse(proxyUrl)
+	f, ok := NewFeed(NotificationsKafka, "notifications-kafka", *baseUrl, 10, 1, username, password, "").(*NotificationsKafkaFeed)
+	require.True(t, ok, "expected a NotificationsKafkaFeed")
+
+	c := &testConsumer{stop: make(chan struct{})}
+	f.newConsumer = func(conf consumer.QueueConfig, handler func(m consumer.Message)) consumer.MessageConsumer {
+		c.conf = conf
+		c.handler = handler
+		return c
+	}
+	return f, c
+}
+
+func TestKafkaNotificationsAreConsumed(t *testing.T) {
+	f, c := newTestKafkaFeed(t, "", "")
+	f.Start()
+	defer f.Stop()
+	lastModified := time.Now().Format(time.RFC3339Nano)
+
+	c.handler(consumer.Message{
+		Headers: map[string]string{"X-Request-Id": "tid_1"},
+		Body:    `{"id": "http://www.ft.com/thing/uuid1", "publishReference": "tid_1", "lastModified": "` + lastModified + `"}`,
+	})
+	c.handler(consumer.Message{
+		Headers: map[string]string{"X-Request-Id": "tid_2", "Message-Timestamp": lastModified},
+		Body:    `[{"id": "http://www.ft.com/thing/uuid2"}, {"id": "http://www.ft.com/thing/uuid3", "publishReference": "tid_3", "lastModified": "` + lastModified + `"}]`,
+	})
+
+	assert.Equal(t, []*Notification{{ID: "http://www.ft.com/thing/uuid1", PublishReference: "tid_1", LastModified: lastModified}}, f.NotificationsFor("uuid1"))
+	assert.Equal(t, []*Notification{{ID: "http://www.ft.com/thing/uuid2", PublishReference: "tid_2", LastModified: lastModified}}, f.NotificationsFor("uuid2"),
+		"the transaction ID and time of the message should be used when the notification has none")
+	assert.Len(t, f.NotificationsFor("uuid3"), 1)
+}
+
+func TestKafkaMessagesWhichAreNotNotificationsAreIgnored(t *testing.T) {
+	f, c := newTestKafkaFeed(t, "", "")
+	f.Start()
+	defer f.Stop()
+
+	c.handler(consumer.Message{Body: `not json`})
+	c.handler(consumer.Message{Body: `{"publishReference": "tid_1"}`})
+
+	assert.Equal(t, 0, f.BufferedUUIDs())
+}
+
+func TestKafkaFeedConsumerConfig(t *testing.T) {
+	f, c := newTestKafkaFeed(t, "user", "pass")
+	f.SetQueueConfig(consumer.QueueConfig{Topic: "Notifications", Offset: "largest"})
+	f.Start()
+	defer f.Stop()
+
+	assert.Equal(t, []string{"http://www.example.org/__kafka-rest-proxy"}, c.conf.Addrs, "the proxy should default to the feed URL")
+	assert.Equal(t, "Notifications", c.conf.Topic)
+	assert.Equal(t, "largest", c.conf.Offset)
+	assert.Equal(t, "PublishAvailabilityMonitor-notifications-kafka-www.example.org", c.conf.Group, "the group should be the same across restarts")
+	assert.Equal(t, "Basic dXNlcjpwYXNz", c.conf.AuthorizationKey)
+
+	msg, err := f.ConnectivityCheck()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", msg)
+}
+
+func TestKafkaFeedsOfDistinctEnvironmentsHaveDistinctGroups(t *testing.T) {
+	f1, c1 := newTestKafkaFeedAt(t, "http://env1.example.org/__kafka-rest-proxy", "", "")
+	f2, c2 := newTestKafkaFeedAt(t, "http://env2.example.org/__kafka-rest-proxy", "", "")
+	f1.Start()
+	defer f1.Stop()
+	f2.Start()
+	defer f2.Stop()
+
+	assert.NotEqual(t, c1.conf.Group, c2.conf.Group, "each environment should receive all the notifications")
+}
+
+func TestKafkaFeedIsConsumingWhileStartedAndReachable(t *testing.T) {
+	f, c := newTestKafkaFeed(t, "", "")
+	assert.False(t, f.IsConsuming(), "the feed is not started")
+
+	f.Start()
+	assert.True(t, f.IsConsuming())
+	c.connectivityErr = errors.New("proxy unreachable")
+	assert.False(t, f.IsConsuming())
+
+	f.Stop()
+	assert.False(t, f.IsConsuming())
+}
+
+func TestKafkaFeedIsNotCheckedOnceStopped(t *testing.T) {
+	f, _ := newTestKafkaFeed(t, "", "")
+	f.Start()
+	f.Stop()
+
+	msg, err := f.ConnectivityCheck()
+	assert.NoError(t, err)
+	assert.Equal(t, "The feed is not started", msg)
+}
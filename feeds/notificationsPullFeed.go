@@ -1,6 +1,7 @@
 package feeds
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 	"sync"
@@ -8,18 +9,34 @@ import (
 
 	"github.com/Financial-Times/publish-availability-monitor/checks"
 	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
-const NotificationsPull = "Notifications-Pull"
+const (
+	NotificationsPull = "Notifications-Pull"
+	// bounds the requests of a single poll, the next poll carries on from there
+	maxPagesPerPoll = 100
+)
+
+var pullFeedGaps = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "pam_pull_feed_gaps_total",
+	Help: "Number of times the notifications pull feed fell behind its expiry window.",
+}, []string{"feed", "url"})
 
 type NotificationsPullFeed struct {
 	baseNotificationsFeed
 	notificationsUrl         string
 	notificationsQueryString string
 	notificationsUrlLock     *sync.Mutex
+	polledQueryString        string //of the last page polled, guarded by notificationsUrlLock
+	polledCount              int    //of the notifications of the last page polled
 	interval                 int
 	ticker                   *time.Ticker
 	poller                   chan struct{}
+	caughtUpAt               time.Time //when the last poll reached the end of the feed
+	gaps                     []Gap
+	gapsLock                 *sync.RWMutex
 }
 
 // ignore unused field (e.g. requestUrl)
@@ -54,61 +71,138 @@ func (f *NotificationsPullFeed) Start() {
 func (f *NotificationsPullFeed) Stop() {
 	log.Infof("shutting down notifications pull feed for %s", f.baseUrl)
 	close(f.poller)
+	f.deleteBufferMetrics()
 }
 
 func (f *NotificationsPullFeed) FeedType() string {
 	return NotificationsPull
 }
 
+// GapsSince returns the periods, ending after t, in which the feed was so far
+// behind that notifications may have expired before they were polled.
+func (f *NotificationsPullFeed) GapsSince(t time.Time) []Gap {
+	f.gapsLock.RLock()
+	defer f.gapsLock.RUnlock()
+
+	var gaps []Gap
+	for _, g := range f.gaps {
+		if g.To.After(t) {
+			gaps = append(gaps, g)
+		}
+	}
+	return gaps
+}
+
+// pollNotificationsFeed follows the next links until the end of the feed, so
+// the feed does not fall behind when there are more notifications than a page.
 func (f *NotificationsPullFeed) pollNotificationsFeed() {
 	f.notificationsUrlLock.Lock()
 	defer f.notificationsUrlLock.Unlock()
 
 	txId := f.buildNotificationsTxId()
-	notificationsUrl := f.notificationsUrl + "?" + f.notificationsQueryString
-	resp, err := f.httpCaller.DoCall(checks.Config{Url: notificationsUrl, Username: f.username, Password: f.password, TxId: txId})
+	caughtUp := false
+	for page := 0; page < maxPagesPerPoll; page++ {
+		queryString := f.notificationsQueryString
+		count, ok := f.pollPage(txId)
+		if !ok {
+			break
+		}
+		if count == 0 || f.notificationsQueryString == queryString {
+			caughtUp = true
+			break
+		}
+	}
+	if !caughtUp {
+		log.WithField("transaction_id", txId).Warnf("Notifications [%s] not polled up to the end of the feed", f.notificationsUrl)
+	}
+
+	f.checkForGap(time.Now(), caughtUp)
+}
+
+// pollPage polls the page at the current query string, moves it to the next
+// page and returns the number of notifications of the page.
+func (f *NotificationsPullFeed) pollPage(txId string) (int, bool) {
+	queryString := f.notificationsQueryString
+	notificationsUrl := f.notificationsUrl + "?" + queryString
+	resp, err := f.httpCaller.DoCall(context.Background(), checks.Config{Url: notificationsUrl, Username: f.username, Password: f.password, TxId: txId})
 
 	if err != nil {
 		log.WithField("transaction_id", txId).WithError(err).Errorf("error calling notifications %s", notificationsUrl)
-		return
+		return 0, false
 	}
 	defer cleanupResp(resp)
 
 	if resp.StatusCode != 200 {
 		log.WithField("transaction_id", txId).Errorf("Notifications [%s] status NOT OK: [%d]", notificationsUrl, resp.StatusCode)
-		return
+		return 0, false
 	}
 
 	var notifications notificationsResponse
 	err = json.NewDecoder(resp.Body).Decode(&notifications)
 	if err != nil {
 		log.WithField("transaction_id", txId).Errorf("Cannot decode json response: [%s]", err.Error())
-		return
+		return 0, false
 	}
 
-	f.notificationsLock.Lock()
-	defer f.notificationsLock.Unlock()
+	f.storeNewNotifications(queryString, notifications.Notifications)
 
-	for _, v := range notifications.Notifications {
-		n := v
-		uuid := parseUuidFromUrl(n.ID)
-		var history []*Notification
-		var found bool
-		if history, found = f.notifications[uuid]; !found {
-			history = make([]*Notification, 0)
-		}
-
-		history = append(history, &n)
-		f.notifications[uuid] = history
+	if len(notifications.Links) == 0 {
+		log.WithField("transaction_id", txId).Errorf("no next url in notifications [%s]", notificationsUrl)
+		return 0, false
 	}
-
 	nextPageUrl, err := url.Parse(notifications.Links[0].Href)
 	if err != nil {
 		log.Errorf("unparseable next url: [%s]", notifications.Links[0].Href)
-		return // and hope that a retry will fix this
+		return 0, false // and hope that a retry will fix this
 	}
 
 	f.notificationsQueryString = nextPageUrl.RawQuery
+	return len(notifications.Notifications), true
+}
+
+// storeNewNotifications buffers the notifications of the page at queryString
+// which were not polled before: once the feed is caught up, its last page is
+// polled again, and starts with the notifications already polled.
+// The notifications delivered twice by the feed are both buffered.
+func (f *NotificationsPullFeed) storeNewNotifications(queryString string, notifications []Notification) {
+	polled := 0
+	if queryString == f.polledQueryString {
+		polled = f.polledCount
+		if polled > len(notifications) {
+			polled = len(notifications)
+		}
+	}
+	f.polledQueryString = queryString
+	f.polledCount = len(notifications)
+
+	f.storeNotifications(notifications[polled:])
+}
+
+// checkForGap records a gap while the feed was last caught up longer ago than
+// its expiry: the notifications of that period are purged as soon as they are
+// polled, so checks cannot rely on their absence.
+func (f *NotificationsPullFeed) checkForGap(now time.Time, caughtUp bool) {
+	f.gapsLock.Lock()
+	defer f.gapsLock.Unlock()
+
+	expiry := time.Duration(f.expiry) * time.Second
+	if now.Sub(f.caughtUpAt) > expiry {
+		if n := len(f.gaps); n > 0 && f.gaps[n-1].From.Equal(f.caughtUpAt) {
+			f.gaps[n-1].To = now
+		} else {
+			log.Warnf("Notifications feed [%s] fell behind its expiry window since [%v]", f.baseUrl, f.caughtUpAt.Format(time.RFC3339))
+			pullFeedGaps.WithLabelValues(f.feedName, f.baseUrl).Inc()
+			f.gaps = append(f.gaps, Gap{From: f.caughtUpAt, To: now})
+		}
+	}
+	if caughtUp {
+		f.caughtUpAt = now
+	}
+
+	earliest := now.Add(-expiry)
+	for len(f.gaps) > 0 && f.gaps[0].To.Before(earliest) {
+		f.gaps = f.gaps[1:]
+	}
 }
 
 func (f *NotificationsPullFeed) buildNotificationsTxId() string {
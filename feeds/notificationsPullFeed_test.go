@@ -2,6 +2,7 @@ package feeds
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,20 +14,23 @@ import (
 	"github.com/Financial-Times/publish-availability-monitor/checks"
 	uuidgen "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockResponse struct {
 	response *http.Response
 	query    *url.Values
+	content  string //the body of response, returned again when the response is repeated
 }
 
 func buildResponse(statusCode int, content string, expectedQuery *url.Values) *mockResponse {
 	return &mockResponse{
-		&http.Response{
+		response: &http.Response{
 			StatusCode: statusCode,
 			Body:       nopCloser{bytes.NewBuffer([]byte(content))},
 		},
-		expectedQuery,
+		query:   expectedQuery,
+		content: content,
 	}
 }
 
@@ -39,10 +43,11 @@ type testHTTPCaller struct {
 	txIdPrefix    string
 	mockResponses []*mockResponse
 	current       int
+	repeatLast    bool //returns the last response again once all were returned, rather than starting over
 }
 
 // returns the mock responses of testHTTPCaller in order
-func (t *testHTTPCaller) DoCall(config checks.Config) (*http.Response, error) {
+func (t *testHTTPCaller) DoCall(ctx context.Context, config checks.Config) (*http.Response, error) {
 	if t.authUser != config.Username || t.authPass != config.Password {
 		return buildResponse(401, `{message: "Not authenticated"}`, nil).response, nil
 	}
@@ -64,7 +69,14 @@ func (t *testHTTPCaller) DoCall(config checks.Config) (*http.Response, error) {
 		assert.Equal(t.t, *response.query, requestUrl.Query())
 	}
 
-	t.current = (t.current + 1) % len(t.mockResponses)
+	if !t.repeatLast || t.current < len(t.mockResponses)-1 {
+		t.current = (t.current + 1) % len(t.mockResponses)
+	}
+	if response.content != "" {
+		resp := *response.response
+		resp.Body = nopCloser{bytes.NewBufferString(response.content)}
+		return &resp, nil
+	}
 	return response.response, nil
 }
 
@@ -73,6 +85,12 @@ func mockHTTPCaller(t *testing.T, txIdPrefix string, responses ...*mockResponse)
 	return &testHTTPCaller{t: t, txIdPrefix: txIdPrefix, mockResponses: responses}
 }
 
+// builds testHTTPCaller with the pages of a notifications feed in the provided order,
+// the last one being polled again once the feed is caught up
+func mockFeedHTTPCaller(t *testing.T, txIdPrefix string, pages ...*mockResponse) checks.HttpCaller {
+	return &testHTTPCaller{t: t, txIdPrefix: txIdPrefix, mockResponses: pages, repeatLast: true}
+}
+
 // builds testHTTPCaller with the given mocked responses in the provided order
 func mockAuthenticatedHTTPCaller(t *testing.T, txIdPrefix string, username string, password string, apiKey string, responses ...*mockResponse) checks.HttpCaller {
 	return &testHTTPCaller{t: t, txIdPrefix: txIdPrefix, authUser: username, authPass: password, apiKey: apiKey, mockResponses: responses}
@@ -93,7 +111,12 @@ func mockNotificationFor(uuid string, publishRef string, lastModified time.Time)
 						"apiUrl": "http://api.ft.com/content/%v",
 						"publishReference": "%v",
 						"lastModified": "%v"
-					}`, uuid, uuid, publishRef, lastModified.Format(time.RFC3339))
+					}`, uuid, uuid, publishRef, lastModified.Format(time.RFC3339Nano))
+}
+
+// mockLastPageFor returns the empty page of a notifications feed which is caught up
+func mockLastPageFor(requestQueryString string) string {
+	return mockNotificationsResponseFor(requestQueryString, "", requestQueryString)
 }
 
 func mockNotificationsResponseFor(requestQueryString string, notifications string, nextLinkQueryString string) string {
@@ -119,7 +142,7 @@ func TestNotificationsArePolled(t *testing.T) {
 		mockNotificationFor(uuid, publishRef, lastModified),
 		"2016-10-28T16:00:00.000Z")
 
-	httpCaller := mockHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(200, notifications, nil))
+	httpCaller := mockFeedHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(200, notifications, nil), buildResponse(200, mockLastPageFor("2016-10-28T16:00:00.000Z"), nil))
 
 	baseUrl, _ := url.Parse("http://www.example.org?type=all")
 	f := NewNotificationsFeed("notifications", *baseUrl, 10, 1, "", "", "")
@@ -146,7 +169,7 @@ func TestMultipleNotificationsAreMapped(t *testing.T) {
 		notifications,
 		"2016-10-28T16:00:00.000Z")
 
-	httpCaller := mockHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(200, response, nil))
+	httpCaller := mockFeedHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(200, response, nil), buildResponse(200, mockLastPageFor("2016-10-28T16:00:00.000Z"), nil))
 
 	baseUrl, _ := url.Parse("http://www.example.org?type=all")
 	f := NewNotificationsFeed("notifications", *baseUrl, 10, 1, "", "", "")
@@ -187,7 +210,7 @@ func TestNotificationsForReturnsAllMatches(t *testing.T) {
 		mockNotificationFor(uuid, publishRef2, lastModified2),
 		"2016-10-28T15:20:00.000Z")
 
-	httpCaller := mockHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(200, notifications1, nil), buildResponse(200, notifications2, nil))
+	httpCaller := mockFeedHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(200, notifications1, nil), buildResponse(200, notifications2, nil), buildResponse(200, mockLastPageFor("2016-10-28T15:20:00.000Z"), nil))
 
 	baseUrl, _ := url.Parse("http://www.example.org")
 	f := NewNotificationsFeed("notifications", *baseUrl, 10, 1, "", "", "")
@@ -210,7 +233,7 @@ func TestNotificationsPollingContinuesAfterErrorResponse(t *testing.T) {
 		mockNotificationFor(uuid, publishRef, lastModified),
 		"2016-10-28T16:00:00.000Z")
 
-	httpCaller := mockHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(500, "", nil), buildResponse(200, notifications, nil))
+	httpCaller := mockFeedHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(500, "", nil), buildResponse(200, notifications, nil), buildResponse(200, mockLastPageFor("2016-10-28T16:00:00.000Z"), nil))
 
 	baseUrl, _ := url.Parse("http://www.example.org")
 	f := NewNotificationsFeed("notifications", *baseUrl, 10, 1, "", "", "")
@@ -227,12 +250,12 @@ func TestNotificationsPollingContinuesAfterErrorResponse(t *testing.T) {
 func TestNotificationsArePurged(t *testing.T) {
 	uuid := uuidgen.NewV4().String()
 	publishRef := "tid_0123wxyz"
-	lastModified := time.Now().Add(time.Duration(-2) * time.Second)
+	lastModified := time.Now().Add(time.Duration(-1) * time.Second)
 	notifications := mockNotificationsResponseFor("2016-10-28T15:00:00.000Z",
 		mockNotificationFor(uuid, publishRef, lastModified),
 		"2016-10-28T16:00:00.000Z")
 
-	httpCaller := mockHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(200, notifications, nil))
+	httpCaller := mockFeedHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(200, notifications, nil), buildResponse(200, mockLastPageFor("2016-10-28T16:00:00.000Z"), nil))
 
 	baseUrl, _ := url.Parse("http://www.example.org")
 	f := NewNotificationsFeed("notifications", *baseUrl, 1, 1, "", "", "")
@@ -268,8 +291,10 @@ func TestNotificationsPollingFollowsOpaqueLink(t *testing.T) {
 	notifications2 := mockNotificationsResponseFor(nextPageQuery.Encode(),
 		mockNotificationFor(uuid2, publishRef2, lastModified2),
 		"page=xxx")
+	lastPageQuery := url.Values{"page": []string{"xxx"}}
+	lastPage := mockLastPageFor(lastPageQuery.Encode())
 
-	httpCaller := mockHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(200, notifications1, nil), buildResponse(200, notifications2, &nextPageQuery))
+	httpCaller := mockFeedHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(200, notifications1, nil), buildResponse(200, notifications2, &nextPageQuery), buildResponse(200, lastPage, &lastPageQuery))
 
 	baseUrl, _ := url.Parse("http://www.example.org")
 	f := NewNotificationsFeed("notifications", *baseUrl, 10, 1, "", "", "")
@@ -286,3 +311,84 @@ func TestNotificationsPollingFollowsOpaqueLink(t *testing.T) {
 	assert.Len(t, response2, 1, "notifications for "+uuid2)
 	assert.Equal(t, publishRef2, response2[0].PublishReference, "publish ref for "+uuid2)
 }
+
+func TestNotificationsPollingDrainsAllPages(t *testing.T) {
+	uuid1 := uuidgen.NewV4().String()
+	uuid2 := uuidgen.NewV4().String()
+	lastModified := time.Now()
+	page1 := mockNotificationsResponseFor("page=1", mockNotificationFor(uuid1, "tid_1", lastModified), "page=2")
+	page2 := mockNotificationsResponseFor("page=2", mockNotificationFor(uuid2, "tid_2", lastModified), "page=3")
+	lastPage := `{"notifications": [], "links": [{"href": "http://api.ft.com/content/notifications?page=3"}]}`
+
+	httpCaller := &testHTTPCaller{t: t, mockResponses: []*mockResponse{
+		buildResponse(200, page1, nil),
+		buildResponse(200, page2, &url.Values{"page": []string{"2"}}),
+		buildResponse(200, lastPage, &url.Values{"page": []string{"3"}}),
+	}}
+
+	baseUrl, _ := url.Parse("http://www.example.org")
+	f := NewNotificationsFeed("notifications", *baseUrl, 10, 1, "", "", "").(*NotificationsPullFeed)
+	f.SetHttpCaller(httpCaller)
+	f.pollNotificationsFeed()
+
+	assert.Len(t, f.NotificationsFor(uuid1), 1, "notifications for "+uuid1)
+	assert.Len(t, f.NotificationsFor(uuid2), 1, "notifications for "+uuid2)
+	assert.Equal(t, "page=3", f.notificationsQueryString, "every page should be polled in a single poll")
+	assert.Empty(t, f.GapsSince(time.Time{}))
+}
+
+func TestNotificationsPollingSkipsRepeatedNotifications(t *testing.T) {
+	uuid := uuidgen.NewV4().String()
+	notifications := mockNotificationsResponseFor("page=1", mockNotificationFor(uuid, "tid_1", time.Now()), "page=1")
+	httpCaller := mockFeedHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(200, mockNotificationsResponseFor("", "", "page=1"), nil), buildResponse(200, notifications, nil))
+
+	baseUrl, _ := url.Parse("http://www.example.org")
+	f := NewNotificationsFeed("notifications", *baseUrl, 10, 1, "", "", "").(*NotificationsPullFeed)
+	f.SetHttpCaller(httpCaller)
+	f.pollNotificationsFeed()
+	f.pollNotificationsFeed()
+	f.pollNotificationsFeed()
+
+	assert.Len(t, f.NotificationsFor(uuid), 1, "the notifications of the last page should not be buffered again when it is polled again")
+}
+
+func TestNotificationsPollingKeepsTheNotificationsDeliveredAgain(t *testing.T) {
+	uuid := uuidgen.NewV4().String()
+	lastModified := time.Now()
+	page1 := mockNotificationsResponseFor("page=1", mockNotificationFor(uuid, "tid_1", lastModified), "page=2")
+	page2 := mockNotificationsResponseFor("page=2", mockNotificationFor(uuid, "tid_1", lastModified), "page=2")
+	page2Again := mockNotificationsResponseFor("page=2", mockNotificationFor(uuid, "tid_1", lastModified)+","+mockNotificationFor(uuid, "tid_1", lastModified), "page=2")
+	httpCaller := mockFeedHTTPCaller(t, "tid_pam_notifications_pull_", buildResponse(200, page1, nil), buildResponse(200, page2, nil), buildResponse(200, page2Again, nil))
+
+	baseUrl, _ := url.Parse("http://www.example.org")
+	f := NewNotificationsFeed("notifications", *baseUrl, 10, 1, "", "", "").(*NotificationsPullFeed)
+	f.SetHttpCaller(httpCaller)
+	f.pollNotificationsFeed()
+	assert.Len(t, f.NotificationsFor(uuid), 2, "a notification delivered again in the next page should be buffered again")
+
+	f.pollNotificationsFeed()
+	assert.Len(t, f.NotificationsFor(uuid), 3, "a notification delivered again after the ones already polled should be buffered again")
+}
+
+func TestNotificationsFeedRecordsGapWhenFallingBehindItsExpiry(t *testing.T) {
+	baseUrl, _ := url.Parse("http://www.example.org")
+	f := NewNotificationsFeed("notifications", *baseUrl, 10, 1, "", "", "").(*NotificationsPullFeed)
+	start := f.caughtUpAt
+
+	f.checkForGap(start.Add(5*time.Second), true)
+	assert.Empty(t, f.GapsSince(time.Time{}), "the feed is within its expiry window")
+
+	f.checkForGap(start.Add(10*time.Second), false)
+	f.checkForGap(start.Add(20*time.Second), false)
+	f.checkForGap(start.Add(30*time.Second), true)
+	gaps := f.GapsSince(time.Time{})
+	require.Len(t, gaps, 1, "the gap should last until the feed caught up")
+	assert.Equal(t, Gap{From: start.Add(5 * time.Second), To: start.Add(30 * time.Second)}, gaps[0])
+	assert.Len(t, f.GapsSince(start.Add(29*time.Second)), 1)
+	assert.Empty(t, f.GapsSince(start.Add(30*time.Second)))
+
+	f.checkForGap(start.Add(40*time.Second), true)
+	assert.Len(t, f.GapsSince(time.Time{}), 1)
+	f.checkForGap(start.Add(50*time.Second), true)
+	assert.Empty(t, f.GapsSince(time.Time{}), "gaps older than the expiry should be purged")
+}
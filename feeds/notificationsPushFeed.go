@@ -1,24 +1,56 @@
 package feeds
 
 import (
-	"bufio"
+	"context"
 	"encoding/json"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/Financial-Times/publish-availability-monitor/checks"
 	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
-const NotificationsPush = "Notifications-Push"
+const (
+	NotificationsPush = "Notifications-Push"
+	// notifications-push sends a heartbeat every 30 seconds
+	defaultHeartbeatTimeout = 90 * time.Second
+)
+
+var (
+	pushFeedReconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pam_push_feed_reconnects_total",
+		Help: "Number of reconnections to the notifications push feeds.",
+	}, []string{"feed", "url"})
+	pushFeedDisconnectedSince = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pam_push_feed_disconnected_since_timestamp_seconds",
+		Help: "Unix time since which the notifications push feed is disconnected, 0 while it is connected.",
+	}, []string{"feed", "url"})
+)
+
+// ConnectionStats describes the connection of a NotificationsPushFeed.
+type ConnectionStats struct {
+	Connected         bool
+	Reconnects        int       //reconnection attempts since the feed was created
+	DisconnectedSince time.Time //zero while connected
+	LastHeartbeat     time.Time
+}
 
 type NotificationsPushFeed struct {
 	baseNotificationsFeed
-	stopFeed     bool
-	stopFeedLock *sync.RWMutex
-	connected    bool
-	apiKey       string
+	stopFeed          bool
+	stopFeedLock      *sync.RWMutex
+	stop              chan struct{} //closed by Stop, to interrupt the wait before reconnecting
+	connected         bool
+	apiKey            string
+	stateLock         *sync.RWMutex
+	lastEventID       string    //sent in the Last-Event-ID header to resume the feed after a reconnection
+	lastHeartbeat     time.Time //or the time of the connection, when no heartbeat was received since
+	heartbeatTimeout  time.Duration
+	reconnect         *backoff
+	reconnects        int
+	disconnectedSince time.Time
 }
 
 func (f *NotificationsPushFeed) Start() {
@@ -27,14 +59,26 @@ func (f *NotificationsPushFeed) Start() {
 	defer f.stopFeedLock.Unlock()
 
 	f.stopFeed = false
+	stop := make(chan struct{})
+	f.stop = stop
 	go func() {
 		if f.httpCaller == nil {
 			f.httpCaller = checks.NewHttpCaller(0)
 		}
 
 		for f.consumeFeed() {
-			time.Sleep(500 * time.Millisecond)
-			log.Info("Disconnected from Push feed! Attempting to reconnect.")
+			delay := f.reconnect.Next()
+			log.Infof("Disconnected from Push feed [%v]! Attempting to reconnect in [%v].", f.baseUrl, delay)
+			select {
+			case <-time.After(delay):
+			case <-stop:
+				log.Infof("Not reconnecting to stopped Push feed [%v]", f.baseUrl)
+				return
+			}
+			if !f.isConsuming() {
+				return
+			}
+			f.reconnecting()
 		}
 	}()
 }
@@ -44,15 +88,104 @@ func (f *NotificationsPushFeed) Stop() {
 	f.stopFeedLock.Lock()
 	defer f.stopFeedLock.Unlock()
 
+	if !f.stopFeed {
+		close(f.stop)
+	}
 	f.stopFeed = true
+	pushFeedDisconnectedSince.DeleteLabelValues(f.feedName, f.baseUrl)
+	f.deleteBufferMetrics()
 }
 
 func (f *NotificationsPushFeed) FeedType() string {
 	return NotificationsPush
 }
 
+// IsConnected tells whether the feed is connected and received a heartbeat
+// within the heartbeat timeout.
 func (f *NotificationsPushFeed) IsConnected() bool {
-	return f.connected
+	f.stateLock.RLock()
+	defer f.stateLock.RUnlock()
+
+	return f.connected && time.Since(f.lastHeartbeat) < f.heartbeatTimeout
+}
+
+// SetHeartbeatTimeout sets how long the feed is considered connected without
+// receiving a heartbeat.
+func (f *NotificationsPushFeed) SetHeartbeatTimeout(timeout time.Duration) {
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+
+	f.heartbeatTimeout = timeout
+}
+
+// SetReconnectPolicy sets how long the feed waits before reconnecting.
+func (f *NotificationsPushFeed) SetReconnectPolicy(policy ReconnectPolicy) {
+	f.reconnect = newBackoff(policy)
+}
+
+// ConnectionStats returns the state of the connection to the feed.
+func (f *NotificationsPushFeed) ConnectionStats() ConnectionStats {
+	f.stateLock.RLock()
+	defer f.stateLock.RUnlock()
+
+	return ConnectionStats{
+		Connected:         f.connected,
+		Reconnects:        f.reconnects,
+		DisconnectedSince: f.disconnectedSince,
+		LastHeartbeat:     f.lastHeartbeat,
+	}
+}
+
+func (f *NotificationsPushFeed) setConnected(connected bool) {
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+
+	f.connected = connected
+	disconnectedSince := 0.0
+	if connected {
+		f.lastHeartbeat = time.Now()
+		f.disconnectedSince = time.Time{}
+	} else {
+		f.disconnectedSince = time.Now()
+		disconnectedSince = float64(f.disconnectedSince.Unix())
+	}
+
+	f.stopFeedLock.RLock()
+	defer f.stopFeedLock.RUnlock()
+	if f.stopFeed {
+		// the series of the feed were deleted when it was stopped
+		return
+	}
+	pushFeedDisconnectedSince.WithLabelValues(f.feedName, f.baseUrl).Set(disconnectedSince)
+}
+
+func (f *NotificationsPushFeed) reconnecting() {
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+
+	f.reconnects++
+	pushFeedReconnects.WithLabelValues(f.feedName, f.baseUrl).Inc()
+}
+
+func (f *NotificationsPushFeed) heartbeat() {
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+
+	f.lastHeartbeat = time.Now()
+}
+
+func (f *NotificationsPushFeed) getLastEventID() string {
+	f.stateLock.RLock()
+	defer f.stateLock.RUnlock()
+
+	return f.lastEventID
+}
+
+func (f *NotificationsPushFeed) setLastEventID(id string) {
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+
+	f.lastEventID = id
 }
 
 func (f *NotificationsPushFeed) isConsuming() bool {
@@ -64,7 +197,11 @@ func (f *NotificationsPushFeed) isConsuming() bool {
 
 func (f *NotificationsPushFeed) consumeFeed() bool {
 	txId := f.buildNotificationsTxId()
-	resp, err := f.httpCaller.DoCall(checks.Config{Url: f.baseUrl, Username: f.username, Password: f.password, ApiKey: f.apiKey, TxId: txId})
+	var headers map[string]string
+	if lastEventID := f.getLastEventID(); lastEventID != "" {
+		headers = map[string]string{"Last-Event-ID": lastEventID}
+	}
+	resp, err := f.httpCaller.DoCall(context.Background(), checks.Config{Url: f.baseUrl, Username: f.username, Password: f.password, ApiKey: f.apiKey, TxId: txId, Headers: headers})
 
 	if err != nil {
 		log.WithField("transaction_id", txId).Errorf("Sending request: [%v]", err)
@@ -78,10 +215,10 @@ func (f *NotificationsPushFeed) consumeFeed() bool {
 	}
 
 	log.WithField("transaction_id", txId).Info("Reconnected to push feed!")
-	f.connected = true
-	defer func() { f.connected = false }()
+	f.setConnected(true)
+	defer f.setConnected(false)
 
-	br := bufio.NewReader(resp.Body)
+	events := newSSEReader(resp.Body, f.getLastEventID())
 	for {
 		if !f.isConsuming() {
 			log.WithField("transaction_id", txId).Info("stop consuming feed")
@@ -89,52 +226,37 @@ func (f *NotificationsPushFeed) consumeFeed() bool {
 		}
 		f.purgeObsoleteNotifications()
 
-		event, err := br.ReadString('\n')
+		event, err := events.Next()
 		if err != nil {
 			log.WithField("transaction_id", txId).Infof("Disconnected from push feed: [%v]", err)
 			return f.isConsuming()
 		}
+		f.setLastEventID(events.LastEventID())
+		// the connection works, so the next reconnection can be attempted quickly
+		f.reconnect.Reset()
 
-		trimmed := strings.TrimSpace(event)
-		if trimmed == "" {
+		if event.isHeartbeat() {
+			f.heartbeat()
+			continue
+		}
+		if event.Event != "message" {
+			log.WithField("transaction_id", txId).Debugf("Ignoring [%v] event", event.Event)
 			continue
 		}
 
-		data := strings.TrimPrefix(trimmed, "data: ")
 		var notifications []Notification
-		err = json.Unmarshal([]byte(data), &notifications)
+		err = json.Unmarshal([]byte(event.Data), &notifications)
 		if err != nil {
 			log.WithField("transaction_id", txId).Errorf("Error: [%v].", err)
 			continue
 		}
 
-		if len(notifications) == 0 {
-			continue
-		}
-
 		f.storeNotifications(notifications)
 	}
 
 	return false
 }
 
-func (f *NotificationsPushFeed) storeNotifications(notifications []Notification) {
-	f.notificationsLock.Lock()
-	defer f.notificationsLock.Unlock()
-
-	for _, n := range notifications {
-		uuid := parseUuidFromUrl(n.ID)
-		var history []*Notification
-		var found bool
-		if history, found = f.notifications[uuid]; !found {
-			history = make([]*Notification, 0)
-		}
-
-		history = append(history, &n)
-		f.notifications[uuid] = history
-	}
-}
-
 func (f *NotificationsPushFeed) buildNotificationsTxId() string {
 	return "tid_pam_notifications_push_" + time.Now().Format(time.RFC3339)
 }
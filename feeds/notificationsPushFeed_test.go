@@ -1,14 +1,18 @@
 package feeds
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/Financial-Times/publish-availability-monitor/checks"
 	log "github.com/Sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockPushNotificationsStream struct {
@@ -19,9 +23,9 @@ type mockPushNotificationsStream struct {
 func (resp *mockPushNotificationsStream) Read(p []byte) (n int, err error) {
 	var data []byte
 	if resp.index >= len(resp.notifications) {
-		data = []byte("data: []\n")
+		data = []byte("data: []\n\n")
 	} else {
-		data = []byte("data: [" + resp.notifications[resp.index] + "]\n")
+		data = []byte("data: [" + resp.notifications[resp.index] + "]\n\n")
 		resp.index++
 		log.Infof("data: %v", string(data))
 	}
@@ -40,10 +44,10 @@ func (resp *mockPushNotificationsStream) Close() error {
 func buildPushResponse(statusCode int, notifications []string) (*mockResponse, *mockPushNotificationsStream) {
 	stream := &mockPushNotificationsStream{notifications, 0}
 	return &mockResponse{
-		&http.Response{
+		response: &http.Response{
 			StatusCode: statusCode,
 			Body:       stream,
-		}, nil}, stream
+		}}, stream
 }
 
 func TestPushNotificationsAreConsumed(t *testing.T) {
@@ -176,3 +180,122 @@ func TestPushNotificationsSendsAuthentication(t *testing.T) {
 	response := f.NotificationsFor(uuid)
 	assert.Len(t, response, 1, "notifications for item")
 }
+
+type recordingHTTPCaller struct {
+	sync.Mutex
+	bodies  []string
+	configs []checks.Config
+}
+
+func (c *recordingHTTPCaller) DoCall(ctx context.Context, config checks.Config) (*http.Response, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	body := ""
+	if len(c.configs) < len(c.bodies) {
+		body = c.bodies[len(c.configs)]
+	} else {
+		time.Sleep(50 * time.Millisecond)
+	}
+	c.configs = append(c.configs, config)
+	return buildResponse(200, body, nil).response, nil
+}
+
+func (c *recordingHTTPCaller) calls() []checks.Config {
+	c.Lock()
+	defer c.Unlock()
+	return append([]checks.Config(nil), c.configs...)
+}
+
+func TestPushFeedResumesFromTheLastEventID(t *testing.T) {
+	uuid := "1cb14245-5185-4ed5-9188-4d2a86085599"
+	notification := strings.Replace(mockNotificationFor(uuid, "tid_0123wxyz", time.Now()), "\n", "", -1)
+	httpCaller := &recordingHTTPCaller{bodies: []string{"id: 42\ndata: [" + notification + "]\n\n"}}
+
+	baseUrl, _ := url.Parse("http://www.example.org")
+	f := NewNotificationsFeed("notifications-push", *baseUrl, 10, 1, "", "", "")
+	f.(*NotificationsPushFeed).SetHttpCaller(httpCaller)
+	f.Start()
+	defer f.Stop()
+	time.Sleep(time.Duration(700) * time.Millisecond)
+
+	assert.Len(t, f.NotificationsFor(uuid), 1, "notifications for item")
+	calls := httpCaller.calls()
+	require.True(t, len(calls) > 1, "the feed should reconnect")
+	assert.Empty(t, calls[0].Headers["Last-Event-ID"])
+	assert.Equal(t, "42", calls[1].Headers["Last-Event-ID"])
+}
+
+func TestPushFeedIsDisconnectedWithoutHeartbeats(t *testing.T) {
+	baseUrl, _ := url.Parse("http://www.example.org")
+	f := NewNotificationsFeed("notifications-push", *baseUrl, 10, 1, "", "", "").(*NotificationsPushFeed)
+	f.SetHeartbeatTimeout(200 * time.Millisecond)
+
+	f.setConnected(true)
+	assert.True(t, f.IsConnected())
+
+	time.Sleep(300 * time.Millisecond)
+	assert.False(t, f.IsConnected(), "the feed should be unhealthy without heartbeat")
+
+	f.heartbeat()
+	assert.True(t, f.IsConnected())
+}
+
+func TestPushFeedBacksOffAndCountsReconnections(t *testing.T) {
+	httpCaller := mockHTTPCaller(t, "tid_pam_notifications_push_", buildResponse(500, "", nil))
+
+	baseUrl, _ := url.Parse("http://www.example.org")
+	f := NewNotificationsFeed("notifications-push", *baseUrl, 10, 1, "", "", "").(*NotificationsPushFeed)
+	f.SetHttpCaller(httpCaller)
+	f.SetReconnectPolicy(ReconnectPolicy{Initial: 10 * time.Millisecond, Max: 80 * time.Millisecond, Multiplier: 2})
+	start := time.Now()
+	f.Start()
+	defer f.Stop()
+	time.Sleep(time.Duration(500) * time.Millisecond)
+
+	stats := f.ConnectionStats()
+	assert.False(t, stats.Connected)
+	assert.True(t, stats.Reconnects >= 4 && stats.Reconnects <= 9, "expected the delays to grow up to the max, got [%d] reconnections", stats.Reconnects)
+	assert.False(t, stats.DisconnectedSince.After(start), "the feed should be disconnected since it was created")
+}
+
+func TestPushFeedConnectionStats(t *testing.T) {
+	baseUrl, _ := url.Parse("http://www.example.org")
+	f := NewNotificationsFeed("notifications-push", *baseUrl, 10, 1, "", "", "").(*NotificationsPushFeed)
+
+	f.setConnected(true)
+	assert.True(t, f.ConnectionStats().DisconnectedSince.IsZero())
+
+	f.setConnected(false)
+	assert.False(t, f.ConnectionStats().DisconnectedSince.IsZero())
+}
+
+func TestPushFeedStopInterruptsTheReconnectionDelay(t *testing.T) {
+	httpCaller := mockHTTPCaller(t, "tid_pam_notifications_push_", buildResponse(500, "", nil))
+
+	baseUrl, _ := url.Parse("http://www.example.org")
+	f := NewNotificationsFeed("notifications-push", *baseUrl, 10, 1, "", "", "").(*NotificationsPushFeed)
+	f.SetHttpCaller(httpCaller)
+	f.SetReconnectPolicy(ReconnectPolicy{Initial: 200 * time.Millisecond, Max: 200 * time.Millisecond, Multiplier: 1})
+	f.Start()
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	f.Stop()
+	time.Sleep(time.Duration(300) * time.Millisecond)
+
+	assert.Equal(t, 0, f.ConnectionStats().Reconnects, "the stopped feed should not reconnect")
+}
+
+func TestPushFeedDisconnectionIsNotExposedOnceStopped(t *testing.T) {
+	httpCaller := mockHTTPCaller(t, "tid_pam_notifications_push_", buildResponse(500, "", nil))
+
+	baseUrl, _ := url.Parse("http://stopped.example.org")
+	f := NewNotificationsFeed("notifications-push", *baseUrl, 10, 1, "", "", "").(*NotificationsPushFeed)
+	f.SetHttpCaller(httpCaller)
+	f.SetReconnectPolicy(ReconnectPolicy{Initial: time.Minute, Max: time.Minute, Multiplier: 1})
+	f.Start()
+	f.Stop()
+	// as when the connection to the feed ends after it was stopped
+	f.setConnected(false)
+
+	assert.False(t, pushFeedDisconnectedSince.DeleteLabelValues("notifications-push", baseUrl.String()), "the series of the stopped feed should not be exposed again")
+}
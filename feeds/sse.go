@@ -0,0 +1,90 @@
+package feeds
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+const heartbeatEvent = "heartbeat"
+
+// sseEvent is an event of a Server-Sent Events stream.
+type sseEvent struct {
+	ID    string
+	Event string //"message" unless the event has an event field
+	Data  string
+}
+
+// isHeartbeat tells whether e is a heartbeat rather than notifications:
+// notifications-push sends an empty list of notifications when there is no publish.
+func (e sseEvent) isHeartbeat() bool {
+	if e.Event == heartbeatEvent {
+		return true
+	}
+	data := strings.Join(strings.Fields(e.Data), "")
+	return data == "" || data == "[]"
+}
+
+// sseReader reads the events of a Server-Sent Events stream, as specified in
+// https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+type sseReader struct {
+	r           *bufio.Reader
+	lastEventID string
+}
+
+func newSSEReader(r io.Reader, lastEventID string) *sseReader {
+	return &sseReader{r: bufio.NewReader(r), lastEventID: lastEventID}
+}
+
+// Next returns the next event of the stream. The events which are not complete
+// when the stream ends are discarded.
+func (s *sseReader) Next() (sseEvent, error) {
+	var data []string
+	event := ""
+	hasData := false
+	for {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			return sseEvent{}, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if !hasData {
+				// an event without data is not dispatched
+				event = ""
+				continue
+			}
+			if event == "" {
+				event = "message"
+			}
+			return sseEvent{ID: s.lastEventID, Event: event, Data: strings.Join(data, "\n")}, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "data":
+			data = append(data, value)
+			hasData = true
+		case "event":
+			event = value
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				s.lastEventID = value
+			}
+		}
+	}
+}
+
+// LastEventID returns the ID of the last event read, which is sent in the
+// Last-Event-ID header to resume the stream after it.
+func (s *sseReader) LastEventID() string {
+	return s.lastEventID
+}
@@ -0,0 +1,46 @@
+package feeds
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEReaderReadsMultiLineEvents(t *testing.T) {
+	stream := ": comment\r\n" +
+		"id: 1\r\n" +
+		"data: [{\"id\": \"a\",\r\n" +
+		"data:  \"publishReference\": \"tid_1\"}]\r\n" +
+		"\r\n" +
+		"event: heartbeat\n" +
+		"data\n" +
+		"\n" +
+		"id: 3\n" +
+		"\n" +
+		"data: incomplete"
+	r := newSSEReader(strings.NewReader(stream), "0")
+
+	event, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, sseEvent{ID: "1", Event: "message", Data: "[{\"id\": \"a\",\n \"publishReference\": \"tid_1\"}]"}, event)
+	assert.False(t, event.isHeartbeat())
+
+	event, err = r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "heartbeat", event.Event)
+	assert.Equal(t, "", event.Data)
+	assert.True(t, event.isHeartbeat())
+
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err, "incomplete events should be discarded")
+	assert.Equal(t, "3", r.LastEventID(), "the ID of events without data should still be tracked")
+}
+
+func TestEmptyNotificationListsAreHeartbeats(t *testing.T) {
+	assert.True(t, sseEvent{Event: "message", Data: "[]"}.isHeartbeat())
+	assert.True(t, sseEvent{Event: "message", Data: "[ ]\n"}.isHeartbeat())
+	assert.False(t, sseEvent{Event: "message", Data: `[{"id": "a"}]`}.isHeartbeat())
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/feeds"
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	feedConnected    = "connected"
+	feedDisconnected = "disconnected"
+	feedPolling      = "polling"
+	feedConsuming    = "consuming"
+)
+
+// feedStatus is the JSON representation of a subscribed notifications feed.
+type feedStatus struct {
+	Environment       string             `json:"environment"`
+	Name              string             `json:"name"`
+	Type              string             `json:"type"`
+	URL               string             `json:"url"`
+	Connection        string             `json:"connection"` //"connected" or "disconnected" for push feeds, "polling" for pull feeds, "consuming" or "disconnected" for Kafka feeds
+	Reconnects        int                `json:"reconnects,omitempty"`
+	DisconnectedSince *time.Time         `json:"disconnectedSince,omitempty"`
+	LastHeartbeat     *time.Time         `json:"lastHeartbeat,omitempty"`
+	Gaps              []feedGap          `json:"gaps,omitempty"`
+	BufferedUUIDs     int                `json:"bufferedUuids"`
+	Notifications     []feedNotification `json:"notifications,omitempty"` //the buffered history of the UUID looked up
+}
+
+type feedGap struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type feedNotification struct {
+	Type             string `json:"type"`
+	ID               string `json:"id"`
+	PublishReference string `json:"publishReference"`
+	LastModified     string `json:"lastModified"`
+}
+
+// feedsHandler lists the notifications feeds subscribed in every environment,
+// filtered by the query parameters environment and name.
+// With the uuid query parameter, the notifications buffered for the UUID are
+// listed for each feed.
+func feedsHandler(subscribed func() map[string][]feeds.Feed) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		envFilter := params.Get("environment")
+		nameFilter := params.Get("name")
+		uuid := params.Get("uuid")
+
+		statuses := []feedStatus{}
+		for envName, envFeeds := range subscribed() {
+			if envFilter != "" && envName != envFilter {
+				continue
+			}
+			for _, f := range envFeeds {
+				if nameFilter != "" && f.FeedName() != nameFilter {
+					continue
+				}
+				statuses = append(statuses, newFeedStatus(envName, f, uuid))
+			}
+		}
+		sort.Slice(statuses, func(i, j int) bool {
+			if statuses[i].Environment != statuses[j].Environment {
+				return statuses[i].Environment < statuses[j].Environment
+			}
+			return statuses[i].Name < statuses[j].Name
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(statuses); err != nil {
+			log.WithError(err).Error("Cannot write notifications feeds")
+		}
+	}
+}
+
+func newFeedStatus(envName string, f feeds.Feed, uuid string) feedStatus {
+	status := feedStatus{
+		Environment: envName,
+		Name:        f.FeedName(),
+		Type:        f.FeedType(),
+		URL:         f.FeedURL(),
+		Connection:  feedPolling,
+	}
+
+	if push, ok := f.(*feeds.NotificationsPushFeed); ok {
+		stats := push.ConnectionStats()
+		status.Connection = feedDisconnected
+		if push.IsConnected() {
+			status.Connection = feedConnected
+		}
+		status.Reconnects = stats.Reconnects
+		if !stats.DisconnectedSince.IsZero() {
+			status.DisconnectedSince = &stats.DisconnectedSince
+		}
+		if !stats.LastHeartbeat.IsZero() {
+			status.LastHeartbeat = &stats.LastHeartbeat
+		}
+	}
+	if kafka, ok := f.(*feeds.NotificationsKafkaFeed); ok {
+		status.Connection = feedDisconnected
+		if kafka.IsConsuming() {
+			status.Connection = feedConsuming
+		}
+	}
+	if gapReporter, ok := f.(feeds.GapReporter); ok {
+		for _, g := range gapReporter.GapsSince(time.Time{}) {
+			status.Gaps = append(status.Gaps, feedGap{g.From, g.To})
+		}
+	}
+	if buffered, ok := f.(feeds.BufferedFeed); ok {
+		status.BufferedUUIDs = buffered.BufferedUUIDs()
+	}
+
+	if uuid != "" {
+		for _, n := range f.NotificationsFor(uuid) {
+			status.Notifications = append(status.Notifications, feedNotification{n.Type, n.ID, n.PublishReference, n.LastModified})
+		}
+	}
+	return status
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/feeds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedsHandlerListsSubscribedFeeds(t *testing.T) {
+	handler := feedsHandler(newFeedsHandlerTestFeeds)
+
+	statuses := getFeedStatuses(t, handler, "/__feeds")
+
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "env1", statuses[0].Environment)
+	assert.Equal(t, "list-notifications", statuses[0].Name)
+	assert.Equal(t, "env1", statuses[1].Environment)
+	assert.Equal(t, "notifications", statuses[1].Name)
+	assert.Equal(t, feeds.NotificationsPull, statuses[1].Type)
+	assert.Equal(t, feedPolling, statuses[1].Connection)
+	assert.Len(t, statuses[1].Gaps, 1)
+	assert.Equal(t, "env2", statuses[2].Environment)
+	assert.Equal(t, "notifications-push", statuses[2].Name)
+	assert.Equal(t, feeds.NotificationsPush, statuses[2].Type)
+	assert.Equal(t, feedDisconnected, statuses[2].Connection)
+	assert.NotNil(t, statuses[2].DisconnectedSince)
+	assert.Equal(t, 0, statuses[2].BufferedUUIDs)
+	for _, s := range statuses {
+		assert.Empty(t, s.Notifications, "notifications should only be listed for a UUID")
+	}
+}
+
+func TestFeedsHandlerFiltersFeeds(t *testing.T) {
+	handler := feedsHandler(newFeedsHandlerTestFeeds)
+
+	statuses := getFeedStatuses(t, handler, "/__feeds?environment=env1&name=notifications")
+
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "env1", statuses[0].Environment)
+	assert.Equal(t, "notifications", statuses[0].Name)
+}
+
+func TestFeedsHandlerLooksUpNotificationsByUUID(t *testing.T) {
+	handler := feedsHandler(newFeedsHandlerTestFeeds)
+
+	statuses := getFeedStatuses(t, handler, "/__feeds?name=notifications&uuid=uuid1")
+
+	require.Len(t, statuses, 1)
+	require.Len(t, statuses[0].Notifications, 1)
+	assert.Equal(t, feedNotification{feeds.UpdateNotification, "http://www.ft.com/thing/uuid1", "tid_1", "2016-10-28T14:00:00.000Z"}, statuses[0].Notifications[0])
+}
+
+func TestFeedsHandlerReportsKafkaFeedsWhichAreNotConsumingAsDisconnected(t *testing.T) {
+	proxyURL, _ := url.Parse("http://env1.example.org/__kafka-rest-proxy")
+	kafka := feeds.NewFeed(feeds.NotificationsKafka, "notifications-kafka", *proxyURL, 10, 1, "", "", "")
+	handler := feedsHandler(func() map[string][]feeds.Feed {
+		return map[string][]feeds.Feed{"env1": {kafka}}
+	})
+
+	statuses := getFeedStatuses(t, handler, "/__feeds")
+
+	require.Len(t, statuses, 1)
+	assert.Equal(t, feeds.NotificationsKafka, statuses[0].Type)
+	assert.Equal(t, feedDisconnected, statuses[0].Connection, "the feed is not started")
+}
+
+func newFeedsHandlerTestFeeds() map[string][]feeds.Feed {
+	n := feeds.Notification{Type: feeds.UpdateNotification, ID: "http://www.ft.com/thing/uuid1", PublishReference: "tid_1", LastModified: "2016-10-28T14:00:00.000Z"}
+	now := time.Now()
+	pushURL, _ := url.Parse("http://env2.example.org/content/notifications-push")
+	return map[string][]feeds.Feed{
+		"env1": {
+			testGappyFeed{mockFeed("notifications", "uuid1", []*feeds.Notification{&n}), []feeds.Gap{{From: now.Add(-time.Hour), To: now}}},
+			mockFeed("list-notifications", "", nil),
+		},
+		"env2": {
+			feeds.NewNotificationsFeed("notifications-push", *pushURL, 10, 1, "", "", ""),
+		},
+	}
+}
+
+func getFeedStatuses(t *testing.T, handler func(w http.ResponseWriter, r *http.Request), target string) []feedStatus {
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", target, nil))
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var statuses []feedStatus
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&statuses))
+	return statuses
+}
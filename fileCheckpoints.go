@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	defaultCheckpointPath = "pending-checks.db"
+	// the checkpoint file is compacted when it has this many lines more than the pending checkpoints
+	checkpointCompactionSlack = 1000
+)
+
+// fileCheckpoints is a CheckpointStore which survives restarts: saved and
+// removed checkpoints are appended to a file as JSON lines, and the file is
+// compacted to the pending checkpoints when it grows too long.
+type fileCheckpoints struct {
+	*memoryCheckpoints
+	file *jsonLinesFile
+}
+
+func newFileCheckpoints(path string) (*fileCheckpoints, error) {
+	if path == "" {
+		path = defaultCheckpointPath
+	}
+
+	c := &fileCheckpoints{memoryCheckpoints: newMemoryCheckpoints(), file: &jsonLinesFile{path: path, kind: "checkpoint"}}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if err := c.compact(); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Loaded [%d] pending checks from checkpoint file [%s]", len(c.pending), path)
+	return c, nil
+}
+
+// load replays the checkpoint file.
+func (c *fileCheckpoints) load() error {
+	_, err := c.file.load(func(line []byte) bool {
+		var cp checkpoint
+		if err := json.Unmarshal(line, &cp); err != nil || cp.ID == 0 {
+			return false
+		}
+
+		if cp.Done {
+			delete(c.pending, cp.ID)
+		} else {
+			c.pending[cp.ID] = cp
+		}
+		if cp.ID > c.lastID {
+			c.lastID = cp.ID
+		}
+		return true
+	})
+	return err
+}
+
+func (c *fileCheckpoints) Save(cp checkpoint) (uint64, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	cp = c.add(cp)
+	return cp.ID, c.file.append(cp)
+}
+
+func (c *fileCheckpoints) Remove(id uint64) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if _, found := c.pending[id]; !found {
+		return nil
+	}
+	delete(c.pending, id)
+	if c.file.lines > 2*len(c.pending)+checkpointCompactionSlack {
+		return c.compact()
+	}
+	return c.file.append(checkpoint{ID: id, Done: true})
+}
+
+func (c *fileCheckpoints) Durable() bool {
+	return true
+}
+
+func (c *fileCheckpoints) Close() error {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.file.close()
+}
+
+// compact rewrites the checkpoint file with the pending checkpoints.
+// Callers must hold the lock.
+func (c *fileCheckpoints) compact() error {
+	return c.file.compact(func(write func(v interface{}) error) error {
+		for _, cp := range c.sorted() {
+			if err := write(cp); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const defaultHistoryPath = "publish-history.db"
+
+// fileHistory is a PublishHistory which survives restarts: every result is
+// appended to a file as a JSON line, and the file is compacted when results
+// fall out of the retention period.
+// Queries are served from memory.
+type fileHistory struct {
+	*memoryHistory
+	file *jsonLinesFile
+}
+
+func newFileHistory(path string, retention time.Duration) (*fileHistory, error) {
+	if path == "" {
+		path = defaultHistoryPath
+	}
+
+	h := &fileHistory{memoryHistory: newMemoryHistory(retention), file: &jsonLinesFile{path: path, kind: "history"}}
+	skipped, err := h.load()
+	if err != nil {
+		return nil, err
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	if h.purge() || skipped > 0 {
+		err = h.compact()
+	} else {
+		err = h.file.open()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Loaded [%d] publish results from history file [%s]", len(h.records), path)
+	return h, nil
+}
+
+// load reads the records already stored in the history file, returning the
+// number of lines which could not be read.
+func (h *fileHistory) load() (int, error) {
+	return h.file.load(func(line []byte) bool {
+		var r historyRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			return false
+		}
+
+		r.Outcome = r.outcome()
+		h.records = append(h.records, r)
+		if r.ID > h.lastID {
+			h.lastID = r.ID
+		}
+		return true
+	})
+}
+
+func (h *fileHistory) Add(pm PublishMetric) error {
+	h.Lock()
+	defer h.Unlock()
+
+	purged := h.purgeIfDue()
+	r := h.append(pm)
+	if purged {
+		return h.compact()
+	}
+	return h.file.append(r)
+}
+
+func (h *fileHistory) Close() error {
+	h.Lock()
+	defer h.Unlock()
+
+	return h.file.close()
+}
+
+// compact rewrites the history file with the records currently in memory.
+// Callers must hold the lock.
+func (h *fileHistory) compact() error {
+	return h.file.compact(func(write func(v interface{}) error) error {
+		for _, r := range h.records {
+			if err := write(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
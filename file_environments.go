@@ -190,6 +190,9 @@ func updateValidationCredentials(data []byte) error {
 }
 
 func configureFileFeeds(envMap map[string]Environment, removedEnvs []string) {
+	subscribedFeedsLock.Lock()
+	defer subscribedFeedsLock.Unlock()
+
 	for _, envName := range removedEnvs {
 		feeds, found := subscribedFeeds[envName]
 		if found {
@@ -225,9 +228,12 @@ func configureFileFeeds(envMap map[string]Environment, removedEnvs []string) {
 					continue
 				}
 
-				interval := appConfig.Threshold / metric.Granularity
+				threshold := appConfig.maxThresholdAt(metric)
+				interval := checkIntervalFor(metric, threshold)
+				expiry := threshold + appConfig.GraceWindow
 
-				if f := feeds.NewNotificationsFeed(metric.Alias, *endpointUrl, appConfig.Threshold, interval, env.Username, env.Password, metric.ApiKey); f != nil {
+				if f := feeds.NewFeed(metric.feedType(), metric.Alias, *endpointUrl, expiry, interval, env.Username, env.Password, metric.ApiKey); f != nil {
+					configureFeed(f, metric)
 					subscribedFeeds[env.Name] = append(envFeeds, f)
 					f.Start()
 				}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/Financial-Times/publish-availability-monitor/jsonpath"
+	log "github.com/Sirupsen/logrus"
+)
+
+// GenericCheckConfig describes how to tell that an operation finished at an
+// endpoint, so that it can be checked without an endpoint specific check.
+// The paths are JSON paths in the response, ex. "$.identifiers[*].value".
+type GenericCheckConfig struct {
+	SuccessStatusCodes []int  `json:"successStatusCodes,omitempty"` //statuses of the available content, default [200]
+	DeletedStatusCodes []int  `json:"deletedStatusCodes,omitempty"` //statuses of the content once deleted, default [404]
+	UUIDPath           string `json:"uuidPath,omitempty"`           //a value ending with the UUID, ex. "$.id"
+	TIDPath            string `json:"tidPath,omitempty"`            //the transaction ID of the operation, ex. "$.publishReference"
+	LastModifiedPath   string `json:"lastModifiedPath,omitempty"`   //the date of the operation, to handle rapid-fire publishes, ex. "$.lastModified"
+}
+
+// GenericCheck implements the EndpointSpecificCheck interface for the endpoints
+// with a GenericCheckConfig.
+// When neither the TID nor the lastModified path is set, the operation is
+// finished as soon as the endpoint responds with a success status.
+type GenericCheck struct {
+	httpCaller         checks.HttpCaller
+	successStatusCodes []int
+	deletedStatusCodes []int
+	uuidPath           jsonpath.Path
+	tidPath            jsonpath.Path
+	lastModifiedPath   jsonpath.Path
+}
+
+func newGenericCheck(httpCaller checks.HttpCaller, conf GenericCheckConfig) (GenericCheck, error) {
+	check := GenericCheck{
+		httpCaller:         httpCaller,
+		successStatusCodes: conf.SuccessStatusCodes,
+		deletedStatusCodes: conf.DeletedStatusCodes,
+	}
+	if len(check.successStatusCodes) == 0 {
+		check.successStatusCodes = []int{200}
+	}
+	if len(check.deletedStatusCodes) == 0 {
+		check.deletedStatusCodes = []int{404}
+	}
+
+	var err error
+	if check.uuidPath, err = jsonpath.Parse(conf.UUIDPath); err != nil {
+		return check, err
+	}
+	if check.tidPath, err = jsonpath.Parse(conf.TIDPath); err != nil {
+		return check, err
+	}
+	check.lastModifiedPath, err = jsonpath.Parse(conf.LastModifiedPath)
+	return check, err
+}
+
+// registerGenericChecks checks the endpoints configured with a generic check
+// with it, instead of the endpoint specific check of their alias.
+func registerGenericChecks(metrics []MetricConfig) {
+	hC := checks.NewHttpCaller(10)
+	for _, metric := range metrics {
+		if metric.Check == nil {
+			continue
+		}
+		check, err := newGenericCheck(hC, *metric.Check)
+		if err != nil {
+			log.Errorf("Invalid check for the endpoint with alias [%v]: [%v]", metric.Alias, err)
+			continue
+		}
+		endpointSpecificChecks[metric.Alias] = check
+	}
+}
+
+func (g GenericCheck) isCurrentOperationFinished(ctx context.Context, pc *PublishCheck) (operationFinished, ignoreCheck bool) {
+	pm := pc.Metric
+	url := pm.endpoint.String() + pm.UUID
+	resp, err := g.httpCaller.DoCall(ctx, checks.Config{Url: url, Username: pc.username, Password: pc.password, ApiKey: pm.config.ApiKey, TxId: checks.ConstructPamTxId(pm.tid)})
+	if err != nil {
+		pc.logger().Warnf("Error calling URL: [%v] : [%v]", url, err.Error())
+		return false, false
+	}
+	defer cleanupResp(resp)
+
+	if pm.isMarkedDeleted {
+		pc.logger().Infof("Content Marked deleted. Status code [%v]", resp.StatusCode)
+		return checks.ContainsStatus(g.deletedStatusCodes, resp.StatusCode), false
+	}
+
+	if !checks.ContainsStatus(g.successStatusCodes, resp.StatusCode) {
+		if resp.StatusCode != 404 {
+			pc.logger().Infof("Status code [%v]", resp.StatusCode)
+		}
+		return false, false
+	}
+	if g.uuidPath == nil && g.tidPath == nil && g.lastModifiedPath == nil {
+		return true, false
+	}
+
+	var jsonResp interface{}
+	if err = json.NewDecoder(resp.Body).Decode(&jsonResp); err != nil {
+		pc.logger().Warnf("Cannot unmarshal JSON response: [%s]", err.Error())
+		return false, false
+	}
+
+	if g.uuidPath != nil && !g.uuidPath.Matches(jsonResp, func(v string) bool { return strings.HasSuffix(v, pm.UUID) }) {
+		pc.logger().Warnf("The response is not about the UUID, at [%v]", g.uuidPath)
+		return false, false
+	}
+	if g.tidPath == nil && g.lastModifiedPath == nil {
+		return true, false
+	}
+
+	if g.tidPath != nil && g.tidPath.Matches(jsonResp, func(v string) bool { return v == pm.tid }) {
+		pc.logger().Info("Matched publish reference.")
+		return true, false
+	}
+	if g.lastModifiedPath == nil {
+		return false, false
+	}
+
+	// look for rapid-fire publishes
+	for _, v := range g.lastModifiedPath.Lookup(jsonResp) {
+		lastModifiedAsString, _ := v.(string)
+		lastModified, err := time.Parse(dateLayout, lastModifiedAsString)
+		if err != nil {
+			continue
+		}
+		if lastModified.After(pm.publishDate) {
+			pc.logger().Infof("Last modified date [%v] is after publish date [%v]", lastModified, pm.publishDate)
+			return false, true
+		}
+		if lastModified.Equal(pm.publishDate) {
+			pc.logger().Infof("Last modified date [%v] is equal to publish date [%v]", lastModified, pm.publishDate)
+			return true, false
+		}
+		pc.logger().Infof("Last modified date [%v] is before publish date [%v]", lastModified, pm.publishDate)
+		return false, false
+	}
+	pc.logger().Warnf("No valid last modified date at [%v]. Skip checking rapid-fire publishes.", g.lastModifiedPath)
+	return false, false
+}
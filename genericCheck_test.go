@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGenericCheck(t *testing.T, conf GenericCheckConfig, responseStatus int, response string) GenericCheck {
+	check, err := newGenericCheck(mockHTTPCaller(t, "tid_pam_1234", buildResponse(responseStatus, response)), conf)
+	require.NoError(t, err)
+	return check
+}
+
+func TestGenericCheckMatchesTheConfiguredPaths(t *testing.T) {
+	conf := GenericCheckConfig{UUIDPath: "$.identifiers[*].value", TIDPath: "$.meta.publishReference"}
+	response := `{"identifiers": [{"value": "other"}, {"value": "http://api.ft.com/things/1234-1234"}], "meta": {"publishReference": "tid_1234"}}`
+	pm := newPublishMetricBuilder().withUUID("1234-1234").withTID("tid_1234").build()
+
+	finished, ignore := newTestGenericCheck(t, conf, 200, response).isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
+	assert.True(t, finished, "operation should have finished")
+	assert.False(t, ignore)
+
+	pm = newPublishMetricBuilder().withUUID("5678-5678").withTID("tid_1234").build()
+	finished, _ = newTestGenericCheck(t, conf, 200, response).isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
+	assert.False(t, finished, "the response should be about the UUID checked")
+}
+
+func TestGenericCheckHandlesRapidFirePublishes(t *testing.T) {
+	conf := GenericCheckConfig{TIDPath: "publishReference", LastModifiedPath: "lastModified"}
+	publishDate := time.Date(2017, 2, 8, 10, 0, 0, 0, time.UTC)
+	pm := newPublishMetricBuilder().withTID("tid_1234").withPublishDate(publishDate).build()
+
+	_, ignore := newTestGenericCheck(t, conf, 200, `{"publishReference": "tid_1235", "lastModified": "2017-02-08T10:00:01Z"}`).
+		isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
+	assert.True(t, ignore, "the check should be ignored once the content was published again")
+
+	finished, _ := newTestGenericCheck(t, conf, 200, `{"publishReference": "tid_1235", "lastModified": "2017-02-08T10:00:00Z"}`).
+		isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
+	assert.True(t, finished, "content last modified at the publish date should be finished")
+
+	finished, ignore = newTestGenericCheck(t, conf, 200, `{"publishReference": "tid_1233", "lastModified": "2017-02-08T09:59:59Z"}`).
+		isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
+	assert.False(t, finished)
+	assert.False(t, ignore)
+}
+
+func TestGenericCheckStatusCodes(t *testing.T) {
+	conf := GenericCheckConfig{SuccessStatusCodes: []int{200, 203}, DeletedStatusCodes: []int{410}}
+	pm := newPublishMetricBuilder().withTID("tid_1234").build()
+
+	finished, _ := newTestGenericCheck(t, conf, 203, "not json").isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
+	assert.True(t, finished, "without paths, the status should tell that the operation finished")
+
+	pm = newPublishMetricBuilder().withTID("tid_1234").withMarkedDeleted(true).build()
+	finished, _ = newTestGenericCheck(t, conf, 404, "").isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
+	assert.False(t, finished)
+	finished, _ = newTestGenericCheck(t, conf, 410, "").isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
+	assert.True(t, finished, "deleted content should have a deleted status")
+}
+
+func TestRegisterGenericChecks(t *testing.T) {
+	defer func(saved map[string]EndpointSpecificCheck) { endpointSpecificChecks = saved }(endpointSpecificChecks)
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": ContentCheck{}}
+	registerGenericChecks([]MetricConfig{
+		{Alias: "content", Check: &GenericCheckConfig{TIDPath: "publishReference"}},
+		{Alias: "annotations", Check: &GenericCheckConfig{UUIDPath: "$.uuid"}},
+		{Alias: "lists"},
+	})
+
+	assert.IsType(t, GenericCheck{}, endpointSpecificChecks["content"], "configured checks should replace the endpoint specific ones")
+	assert.IsType(t, GenericCheck{}, endpointSpecificChecks["annotations"])
+	assert.NotContains(t, endpointSpecificChecks, "lists")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -23,10 +24,11 @@ type Healthcheck struct {
 	client          *http.Client
 	config          *AppConfig
 	consumer        consumer.MessageConsumer
-	metricContainer *publishHistory
+	metricContainer PublishHistory
+	synthetic       *syntheticPublisher //nil when synthetic publishes are disabled
 }
 
-func newHealthcheck(config *AppConfig, metricContainer *publishHistory) *Healthcheck {
+func newHealthcheck(config *AppConfig, metricContainer PublishHistory) *Healthcheck {
 	httpClient := &http.Client{Timeout: requestTimeout * time.Millisecond}
 	c := consumer.NewConsumer(config.QueueConf, func(m consumer.Message) {}, httpClient)
 	return &Healthcheck{
@@ -62,11 +64,15 @@ var noReadEnvironments = fthealth.Check{
 }
 
 func (h *Healthcheck) checkHealth() func(w http.ResponseWriter, r *http.Request) {
-	checks := make([]fthealth.Check, 4)
+	checks := make([]fthealth.Check, 5)
 	checks[0] = h.messageQueueProxyReachable()
 	checks[1] = h.reflectPublishFailures()
 	checks[2] = h.validationServicesReachable()
 	checks[3] = isConsumingFromPushFeeds()
+	checks[4] = isConsumingFromKafkaFeeds()
+	if h.synthetic != nil {
+		checks = append(checks, h.syntheticPublishesAvailable())
+	}
 
 	readEnvironmentChecks := h.readEnvironmentsReachable()
 	if len(readEnvironmentChecks) == 0 {
@@ -123,12 +129,12 @@ func isConsumingFromPushFeeds() fthealth.Check {
 		Checker: func() (string, error) {
 			var failing []string
 			result := true
-			for _, val := range subscribedFeeds {
+			for _, val := range subscribedFeedsSnapshot() {
 				for _, feed := range val {
 					push, ok := feed.(*feeds.NotificationsPushFeed)
 					if ok && !push.IsConnected() {
 						log.Warnf("Feed \"%s\" with URL \"%s\" is not connected!", feed.FeedName(), feed.FeedURL())
-						failing = append(failing, feed.FeedURL())
+						failing = append(failing, describeDisconnection(feed.FeedURL(), push.ConnectionStats(), time.Now()))
 						result = false
 					}
 				}
@@ -142,6 +148,50 @@ func isConsumingFromPushFeeds() fthealth.Check {
 	}
 }
 
+func isConsumingFromKafkaFeeds() fthealth.Check {
+	return fthealth.Check{
+		ID:               "IsConsumingFromNotificationsKafkaFeeds",
+		BusinessImpact:   "Publish metrics are not recorded. This will impact the SLA measurement.",
+		Name:             "IsConsumingFromNotificationsKafkaFeeds",
+		PanicGuide:       pam_run_book_url,
+		Severity:         1,
+		TechnicalSummary: "The kafka-rest-proxies of the configured notifications Kafka feeds are reachable.",
+		Checker:          checkKafkaFeedsConnectivity,
+	}
+}
+
+// checkKafkaFeedsConnectivity returns an error listing the subscribed feeds
+// whose connectivity check fails.
+func checkKafkaFeedsConnectivity() (string, error) {
+	var failing []string
+	for _, envFeeds := range subscribedFeedsSnapshot() {
+		for _, feed := range envFeeds {
+			checker, ok := feed.(feeds.ConnectivityChecker)
+			if !ok {
+				continue
+			}
+			if _, err := checker.ConnectivityCheck(); err != nil {
+				log.Warnf("Feed \"%s\" with URL \"%s\" is not reachable: [%v]", feed.FeedName(), feed.FeedURL(), err)
+				failing = append(failing, fmt.Sprintf("%s (%v)", feed.FeedURL(), err))
+			}
+		}
+	}
+
+	if len(failing) > 0 {
+		sort.Strings(failing)
+		return "Unreachable feeds detected.", errors.New("At least one of our Notifications Kafka feeds cannot reach its kafka-rest-proxy. Failing feeds: " + strings.Join(failing, ","))
+	}
+	return "", nil
+}
+
+// describeDisconnection tells for how long the push feed at url has been down.
+func describeDisconnection(url string, stats feeds.ConnectionStats, now time.Time) string {
+	if stats.Connected {
+		return fmt.Sprintf("%s (no heartbeat for %v)", url, now.Sub(stats.LastHeartbeat).Truncate(time.Second))
+	}
+	return fmt.Sprintf("%s (disconnected for %v, %d reconnections)", url, now.Sub(stats.DisconnectedSince).Truncate(time.Second), stats.Reconnects)
+}
+
 func (h *Healthcheck) messageQueueProxyReachable() fthealth.Check {
 	return fthealth.Check{
 		ID:               "MessageQueueProxyReachable",
@@ -167,17 +217,31 @@ func (h *Healthcheck) reflectPublishFailures() fthealth.Check {
 
 }
 
+func (h *Healthcheck) syntheticPublishesAvailable() fthealth.Check {
+	return fthealth.Check{
+		ID:               "SyntheticPublishesAvailable",
+		BusinessImpact:   "Publishes may not be available to readers, or the publish checks may not work.",
+		Name:             "SyntheticPublishesAvailable",
+		PanicGuide:       pam_run_book_url,
+		Severity:         2,
+		TechnicalSummary: "The last synthetic publish was not available on time at every endpoint",
+		Checker:          h.synthetic.checkLastRun,
+	}
+}
+
 func (h *Healthcheck) checkForPublishFailures() (string, error) {
-	h.metricContainer.RLock()
+	publishResults, err := h.metricContainer.Query(HistoryQuery{Limit: defaultHistoryLimit})
+	if err != nil {
+		return "", fmt.Errorf("cannot load publish history: %v", err)
+	}
+
 	failures := make(map[string]struct{})
 	var emptyStruct struct{}
-	for i := 0; i < len(h.metricContainer.publishMetrics); i++ {
-
-		if !h.metricContainer.publishMetrics[i].publishOK {
-			failures[h.metricContainer.publishMetrics[i].UUID] = emptyStruct
+	for _, r := range publishResults {
+		if breachesSLA(r.outcome()) {
+			failures[r.UUID] = emptyStruct
 		}
 	}
-	h.metricContainer.RUnlock()
 
 	failureThreshold := 2 //default
 	if h.config.HealthConf.FailureThreshold != 0 {
@@ -185,7 +249,7 @@ func (h *Healthcheck) checkForPublishFailures() (string, error) {
 	}
 
 	if len(failures) >= failureThreshold {
-		return "", fmt.Errorf("%d publish failures happened during the last 10 publishes", len(failures))
+		return "", fmt.Errorf("%d publish failures happened during the last %d publishes", len(failures), defaultHistoryLimit)
 	}
 	return "", nil
 }
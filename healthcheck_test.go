@@ -1,12 +1,14 @@
 package main
 
 import (
+	"errors"
 	"net/url"
-	"sync"
 	"testing"
 	"time"
 
+	"github.com/Financial-Times/publish-availability-monitor/feeds"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuildFtHealthcheckUrl(t *testing.T) {
@@ -58,14 +60,13 @@ func TestPublishNoFailuresForSameUUIDs(t *testing.T) {
 	interval := Interval{5, 5}
 	newUrl := url.URL{}
 	t0 := time.Now()
-	publishMetric1 := PublishMetric{"1234567", false, t0, "", interval, config, newUrl, "tid_1234", false}
-	publishMetric2 := PublishMetric{"1234567", false, t0, "", interval, config, newUrl, "tid_6789", false}
-	publishMetric3 := PublishMetric{"1234567", false, t0, "", interval, config, newUrl, "tid_6789", false}
-	testMetrics := []PublishMetric{publishMetric1, publishMetric2, publishMetric3}
-	testPublishHistory := publishHistory{sync.RWMutex{}, testMetrics}
+	publishMetric1 := PublishMetric{UUID: "1234567", publishOK: false, publishDate: t0, publishInterval: interval, config: config, endpoint: newUrl, tid: "tid_1234"}
+	publishMetric2 := PublishMetric{UUID: "1234567", publishOK: false, publishDate: t0, publishInterval: interval, config: config, endpoint: newUrl, tid: "tid_6789"}
+	publishMetric3 := PublishMetric{UUID: "1234567", publishOK: false, publishDate: t0, publishInterval: interval, config: config, endpoint: newUrl, tid: "tid_6789"}
+	testPublishHistory := newTestPublishHistory(publishMetric1, publishMetric2, publishMetric3)
 	testHealthcheck := Healthcheck{
 		config:          &AppConfig{},
-		metricContainer: &testPublishHistory,
+		metricContainer: testPublishHistory,
 	}
 	_, err := testHealthcheck.checkForPublishFailures()
 
@@ -77,16 +78,116 @@ func TestPublishFailureForDistinctUUIDs(t *testing.T) {
 	interval := Interval{5, 5}
 	newUrl := url.URL{}
 	t0 := time.Now()
-	publishMetric1 := PublishMetric{"12345", false, t0, "", interval, config, newUrl, "tid_1234", false}
-	publishMetric2 := PublishMetric{"12678", false, t0, "", interval, config, newUrl, "tid_6789", false}
-	publishMetric3 := PublishMetric{"12679", true, t0, "", interval, config, newUrl, "tid_6789", false}
-	testMetrics := []PublishMetric{publishMetric1, publishMetric2, publishMetric3}
-	testPublishHistory := publishHistory{sync.RWMutex{}, testMetrics}
+	publishMetric1 := PublishMetric{UUID: "12345", publishOK: false, publishDate: t0, publishInterval: interval, config: config, endpoint: newUrl, tid: "tid_1234"}
+	publishMetric2 := PublishMetric{UUID: "12678", publishOK: false, publishDate: t0, publishInterval: interval, config: config, endpoint: newUrl, tid: "tid_6789"}
+	publishMetric3 := PublishMetric{UUID: "12679", publishOK: true, publishDate: t0, publishInterval: interval, config: config, endpoint: newUrl, tid: "tid_6789"}
+	testPublishHistory := newTestPublishHistory(publishMetric1, publishMetric2, publishMetric3)
 	testHealthcheck := Healthcheck{
 		config:          &AppConfig{},
-		metricContainer: &testPublishHistory,
+		metricContainer: testPublishHistory,
 	}
 	_, err := testHealthcheck.checkForPublishFailures()
 
 	assert.Error(t, err, "Expected Error for at least two distinct uuid publish fails")
 }
+
+func TestPublishFailuresOutsideTheLastPublishesAreIgnored(t *testing.T) {
+	config := MetricConfig{}
+	interval := Interval{5, 5}
+	newUrl := url.URL{}
+	t0 := time.Now()
+	testPublishHistory := newTestPublishHistory(
+		PublishMetric{UUID: "12345", publishOK: false, publishDate: t0, publishInterval: interval, config: config, endpoint: newUrl, tid: "tid_1234"},
+		PublishMetric{UUID: "12678", publishOK: false, publishDate: t0, publishInterval: interval, config: config, endpoint: newUrl, tid: "tid_6789"},
+	)
+	for i := 0; i < defaultHistoryLimit; i++ {
+		testPublishHistory.Add(PublishMetric{UUID: "12679", publishOK: true, publishDate: t0, publishInterval: interval, config: config, endpoint: newUrl, tid: "tid_6789"})
+	}
+	testHealthcheck := Healthcheck{
+		config:          &AppConfig{},
+		metricContainer: testPublishHistory,
+	}
+	_, err := testHealthcheck.checkForPublishFailures()
+
+	assert.NoError(t, err, "No Error expected for failures older than the last publishes")
+}
+
+func TestAbortedPublishesAreNotFailures(t *testing.T) {
+	config := MetricConfig{}
+	interval := Interval{5, 5}
+	newUrl := url.URL{}
+	t0 := time.Now()
+	publishMetric1 := PublishMetric{UUID: "12345", publishOK: false, publishDate: t0, publishInterval: interval, config: config, endpoint: newUrl, tid: "tid_1234", outcome: outcomeAborted}
+	publishMetric2 := PublishMetric{UUID: "12678", publishOK: false, publishDate: t0, publishInterval: interval, config: config, endpoint: newUrl, tid: "tid_6789", outcome: outcomeAborted}
+	testPublishHistory := newTestPublishHistory(publishMetric1, publishMetric2)
+	testHealthcheck := Healthcheck{
+		config:          &AppConfig{},
+		metricContainer: testPublishHistory,
+	}
+	_, err := testHealthcheck.checkForPublishFailures()
+
+	assert.NoError(t, err, "No Error expected for checks aborted by a shutdown")
+}
+
+func TestLatePublishesAreFailures(t *testing.T) {
+	t0 := time.Now()
+	testPublishHistory := newTestPublishHistory(
+		PublishMetric{UUID: "12345", publishDate: t0, tid: "tid_1234", outcome: outcomeLate, latency: 150 * time.Second},
+		PublishMetric{UUID: "12678", publishDate: t0, tid: "tid_6789"},
+	)
+	testHealthcheck := Healthcheck{
+		config:          &AppConfig{},
+		metricContainer: testPublishHistory,
+	}
+	_, err := testHealthcheck.checkForPublishFailures()
+
+	assert.Error(t, err, "Expected Error for a late and a missing publish")
+}
+
+func TestDescribeDisconnection(t *testing.T) {
+	now := time.Now()
+
+	disconnected := feeds.ConnectionStats{DisconnectedSince: now.Add(-200500 * time.Millisecond), Reconnects: 12}
+	assert.Equal(t, "http://env1/push (disconnected for 3m20s, 12 reconnections)", describeDisconnection("http://env1/push", disconnected, now))
+
+	stale := feeds.ConnectionStats{Connected: true, LastHeartbeat: now.Add(-2 * time.Minute)}
+	assert.Equal(t, "http://env1/push (no heartbeat for 2m0s)", describeDisconnection("http://env1/push", stale, now))
+}
+
+type unreachableTestFeed struct {
+	testFeed
+}
+
+func (f unreachableTestFeed) ConnectivityCheck() (string, error) {
+	return "", errors.New("connection refused")
+}
+
+func TestKafkaFeedsHealthcheckListsUnreachableFeeds(t *testing.T) {
+	defer func(saved map[string][]feeds.Feed) { subscribedFeeds = saved }(subscribedFeeds)
+	subscribedFeeds = map[string][]feeds.Feed{
+		"env1": {unreachableTestFeed{testFeed{feedName: "notifications-kafka"}}, mockFeed("notifications", "", nil)},
+	}
+
+	_, err := isConsumingFromKafkaFeeds().Checker()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "notifications-kafka (connection refused)")
+	assert.NotContains(t, err.Error(), "notifications,", "the feeds without connectivity check should be ignored")
+}
+
+func TestKafkaFeedsHealthcheckPassesWithoutKafkaFeeds(t *testing.T) {
+	defer func(saved map[string][]feeds.Feed) { subscribedFeeds = saved }(subscribedFeeds)
+	subscribedFeeds = map[string][]feeds.Feed{"env1": {mockFeed("notifications", "", nil)}}
+
+	_, err := isConsumingFromKafkaFeeds().Checker()
+
+	assert.NoError(t, err)
+}
+
+func newTestPublishHistory(publishMetrics ...PublishMetric) PublishHistory {
+	history := newMemoryHistory(time.Hour)
+	for _, pm := range publishMetrics {
+		history.Add(pm)
+	}
+	return history
+}
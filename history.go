@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	defaultHistoryRetentionDays = 7
+	defaultHistoryLimit         = 10
+	historyPurgeInterval        = time.Hour
+)
+
+// PublishHistory stores the results of publish checks, so they can be queried
+// by the /__history endpoint and by the healthchecks.
+type PublishHistory interface {
+	Add(pm PublishMetric) error
+	// Query returns the stored results matching q, the most recent first.
+	Query(q HistoryQuery) ([]historyRecord, error)
+	Close() error
+}
+
+// HistoryQuery filters the publish history. Empty fields match every result.
+type HistoryQuery struct {
+	UUID          string
+	TransactionID string
+	Environment   string
+	Alias         string
+	Outcome       string
+	From          time.Time //inclusive lower bound of the publish date
+	To            time.Time //exclusive upper bound of the publish date
+	Before        uint64    //only results stored before the one with this ID, used for pagination
+	Limit         int
+}
+
+const (
+	// the content was available within the SLA
+	outcomeOnTime = "on-time"
+	// the content became available after the SLA, within the grace window
+	outcomeLate = "late"
+	// the content was not available by the end of the grace window
+	outcomeMissing = "missing"
+	// the check was stopped before the end of the SLA because the monitor shut down
+	outcomeAborted = "aborted"
+	// the check was pending when the monitor restarted, and could not be resumed
+	outcomeRestarted = "monitor-restarted"
+	// the content was not found in a notifications feed which may have missed it
+	outcomeInconclusive = "inconclusive"
+)
+
+var outcomes = []string{outcomeOnTime, outcomeLate, outcomeMissing, outcomeAborted, outcomeRestarted, outcomeInconclusive}
+
+// the outcomes stored before late publishes were told apart from missing ones
+var legacyOutcomes = map[string]string{"success": outcomeOnTime, "failure": outcomeMissing}
+
+// outcomeOf returns the outcome of pm, which is derived from its publishOK
+// unless the content arrived late or the check could not be completed.
+func outcomeOf(pm PublishMetric) string {
+	if pm.outcome != "" {
+		return pm.outcome
+	}
+	if pm.publishOK {
+		return outcomeOnTime
+	}
+	return outcomeMissing
+}
+
+// breachesSLA tells whether outcome is a publish which was not available
+// within its SLA.
+func breachesSLA(outcome string) bool {
+	return outcome == outcomeLate || outcome == outcomeMissing
+}
+
+// HistoryConfig holds the publish history store configuration
+type HistoryConfig struct {
+	Store         string `json:"store"` //"file" (default) or "memory"
+	Path          string `json:"path"`
+	RetentionDays int    `json:"retentionDays"`
+}
+
+// historyRecord is the stored representation of a PublishMetric.
+type historyRecord struct {
+	ID              uint64    `json:"id"`
+	UUID            string    `json:"uuid"`
+	TransactionID   string    `json:"transactionId"`
+	Environment     string    `json:"environment"`
+	Alias           string    `json:"alias"`
+	Endpoint        string    `json:"endpoint"`
+	PublishDate     time.Time `json:"publishDate"`
+	PublishOK       bool      `json:"publishOk"`
+	Outcome         string    `json:"outcome"`
+	LowerBound      int       `json:"lowerBound"`
+	UpperBound      int       `json:"upperBound"`
+	IsMarkedDeleted bool      `json:"isMarkedDeleted"`
+	ContentType     string    `json:"contentType,omitempty"`
+	Threshold       int       `json:"threshold,omitempty"`
+	Latency         float64   `json:"latency,omitempty"` //seconds between the publish and the content becoming available
+	Findings        []string  `json:"findings,omitempty"`
+}
+
+// NewPublishHistory returns the PublishHistory store described by conf.
+func NewPublishHistory(conf HistoryConfig) (PublishHistory, error) {
+	retentionDays := conf.RetentionDays
+	if retentionDays <= 0 {
+		retentionDays = defaultHistoryRetentionDays
+	}
+	retention := time.Duration(retentionDays) * 24 * time.Hour
+
+	switch conf.Store {
+	case "memory":
+		return newMemoryHistory(retention), nil
+	case "", "file":
+		return newFileHistory(conf.Path, retention)
+	default:
+		return nil, fmt.Errorf("unsupported publish history store [%s]", conf.Store)
+	}
+}
+
+func newHistoryRecord(id uint64, pm PublishMetric) historyRecord {
+	return historyRecord{
+		ID:              id,
+		UUID:            pm.UUID,
+		TransactionID:   pm.tid,
+		Environment:     pm.platform,
+		Alias:           pm.config.Alias,
+		Endpoint:        pm.endpoint.String(),
+		PublishDate:     pm.publishDate,
+		PublishOK:       pm.publishOK,
+		Outcome:         outcomeOf(pm),
+		Latency:         pm.latency.Seconds(),
+		LowerBound:      pm.publishInterval.lowerBound,
+		UpperBound:      pm.publishInterval.upperBound,
+		IsMarkedDeleted: pm.isMarkedDeleted,
+		ContentType:     pm.contentType,
+		Threshold:       pm.threshold,
+		Findings:        pm.findings,
+	}
+}
+
+func (r historyRecord) metric() PublishMetric {
+	endpoint, err := url.Parse(r.Endpoint)
+	if err != nil {
+		endpoint = &url.URL{}
+	}
+
+	return PublishMetric{
+		UUID:            r.UUID,
+		publishOK:       r.PublishOK,
+		outcome:         r.outcome(),
+		latency:         time.Duration(r.Latency * float64(time.Second)),
+		publishDate:     r.PublishDate,
+		platform:        r.Environment,
+		publishInterval: Interval{r.LowerBound, r.UpperBound},
+		config:          MetricConfig{Alias: r.Alias},
+		endpoint:        *endpoint,
+		tid:             r.TransactionID,
+		isMarkedDeleted: r.IsMarkedDeleted,
+		contentType:     r.ContentType,
+		threshold:       r.Threshold,
+		findings:        r.Findings,
+	}
+}
+
+func (r historyRecord) outcome() string {
+	if outcome, found := legacyOutcomes[r.Outcome]; found {
+		return outcome
+	}
+	if r.Outcome != "" {
+		return r.Outcome
+	}
+	if r.PublishOK {
+		return outcomeOnTime
+	}
+	return outcomeMissing
+}
+
+func (q HistoryQuery) matches(r historyRecord) bool {
+	return (q.UUID == "" || q.UUID == r.UUID) &&
+		(q.TransactionID == "" || q.TransactionID == r.TransactionID) &&
+		(q.Environment == "" || q.Environment == r.Environment) &&
+		(q.Alias == "" || q.Alias == r.Alias) &&
+		(q.Outcome == "" || q.Outcome == r.outcome()) &&
+		(q.From.IsZero() || !r.PublishDate.Before(q.From)) &&
+		(q.To.IsZero() || r.PublishDate.Before(q.To)) &&
+		(q.Before == 0 || r.ID < q.Before)
+}
+
+// memoryHistory keeps the publish history in memory, for the retention period.
+type memoryHistory struct {
+	sync.RWMutex
+	records   []historyRecord
+	lastID    uint64
+	retention time.Duration
+	lastPurge time.Time
+}
+
+func newMemoryHistory(retention time.Duration) *memoryHistory {
+	return &memoryHistory{records: make([]historyRecord, 0), retention: retention, lastPurge: time.Now()}
+}
+
+func (h *memoryHistory) Add(pm PublishMetric) error {
+	h.Lock()
+	defer h.Unlock()
+
+	h.purgeIfDue()
+	h.append(pm)
+	return nil
+}
+
+func (h *memoryHistory) append(pm PublishMetric) historyRecord {
+	h.lastID++
+	r := newHistoryRecord(h.lastID, pm)
+	h.records = append(h.records, r)
+	return r
+}
+
+func (h *memoryHistory) Query(q HistoryQuery) ([]historyRecord, error) {
+	h.RLock()
+	defer h.RUnlock()
+
+	result := make([]historyRecord, 0)
+	for i := len(h.records) - 1; i >= 0; i-- {
+		if q.Limit > 0 && len(result) == q.Limit {
+			break
+		}
+		if q.matches(h.records[i]) {
+			result = append(result, h.records[i])
+		}
+	}
+	return result, nil
+}
+
+func (h *memoryHistory) Close() error {
+	return nil
+}
+
+// purgeIfDue purges the history at most once per historyPurgeInterval and
+// reports whether any record was dropped. Callers must hold the lock.
+func (h *memoryHistory) purgeIfDue() bool {
+	if time.Since(h.lastPurge) < historyPurgeInterval {
+		return false
+	}
+	return h.purge()
+}
+
+// purge drops the records published before the retention period and reports
+// whether any record was dropped. Callers must hold the lock.
+func (h *memoryHistory) purge() bool {
+	h.lastPurge = time.Now()
+	earliest := time.Now().Add(-h.retention)
+	kept := make([]historyRecord, 0, len(h.records))
+	for _, r := range h.records {
+		if !r.PublishDate.Before(earliest) {
+			kept = append(kept, r)
+		}
+	}
+
+	purged := len(h.records) - len(kept)
+	if purged == 0 {
+		return false
+	}
+
+	log.Infof("Purging [%d] publish results older than [%v] from history", purged, earliest)
+	h.records = kept
+	return true
+}
+
+func updateHistory(history PublishHistory, newPublishResult PublishMetric) {
+	if err := history.Add(newPublishResult); err != nil {
+		log.Errorf("Cannot store publish result in history: %s, error: [%v]", newPublishResult, err)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	defaultHistoryPageSize = 50
+	maxHistoryPageSize     = 500
+)
+
+// historyPage is the JSON representation of a page of publish history.
+// NextCursor is empty when there are no more results.
+type historyPage struct {
+	Results    []historyRecord `json:"results"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
+
+// jsonError is the JSON body of error responses.
+type jsonError struct {
+	Message string `json:"message"`
+}
+
+// historyHandler serves the publish history as JSON, filtered by the query
+// parameters uuid, tid, environment (or platform), alias, outcome, from and to,
+// and paginated by limit and cursor.
+// Clients accepting only text/plain get the history in the legacy text format.
+func historyHandler(history PublishHistory) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseHistoryQuery(r)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// fetch one more result than requested to know whether there is a next page
+		pageSize := q.Limit
+		q.Limit++
+		records, err := history.Query(q)
+		if err != nil {
+			log.WithError(err).Error("Cannot load publish history")
+			writeJSONError(w, http.StatusInternalServerError, "cannot load publish history")
+			return
+		}
+
+		page := historyPage{Results: records}
+		if len(records) > pageSize {
+			page.Results = records[:pageSize]
+			page.NextCursor = strconv.FormatUint(page.Results[pageSize-1].ID, 10)
+		}
+
+		if acceptsOnlyText(r) {
+			writeHistoryText(w, page)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(page); err != nil {
+			log.WithError(err).Error("Cannot write publish history")
+		}
+	}
+}
+
+func parseHistoryQuery(r *http.Request) (HistoryQuery, error) {
+	params := r.URL.Query()
+	q := HistoryQuery{
+		UUID:          params.Get("uuid"),
+		TransactionID: params.Get("tid"),
+		Environment:   params.Get("environment"),
+		Alias:         params.Get("alias"),
+		Outcome:       params.Get("outcome"),
+		Limit:         defaultHistoryPageSize,
+	}
+	if q.Environment == "" {
+		q.Environment = params.Get("platform")
+	}
+	if acceptsOnlyText(r) {
+		q.Limit = defaultHistoryLimit
+	}
+
+	if outcome, found := legacyOutcomes[q.Outcome]; found {
+		q.Outcome = outcome
+	} else if q.Outcome != "" && !validType(outcomes, q.Outcome) {
+		return q, fmt.Errorf("invalid outcome [%s], expected one of [%s]", q.Outcome, strings.Join(queryableOutcomes(), ", "))
+	}
+
+	var err error
+	if q.From, err = parseHistoryTime(params.Get("from")); err != nil {
+		return q, fmt.Errorf("invalid from [%s], expected an RFC3339 date", params.Get("from"))
+	}
+	if q.To, err = parseHistoryTime(params.Get("to")); err != nil {
+		return q, fmt.Errorf("invalid to [%s], expected an RFC3339 date", params.Get("to"))
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		q.Limit, err = strconv.Atoi(limit)
+		if err != nil || q.Limit <= 0 || q.Limit > maxHistoryPageSize {
+			return q, fmt.Errorf("invalid limit [%s], expected a number between 1 and %d", limit, maxHistoryPageSize)
+		}
+	}
+
+	if cursor := params.Get("cursor"); cursor != "" {
+		q.Before, err = strconv.ParseUint(cursor, 10, 64)
+		if err != nil || q.Before == 0 {
+			return q, fmt.Errorf("invalid cursor [%s]", cursor)
+		}
+	}
+
+	return q, nil
+}
+
+// queryableOutcomes returns the outcomes the history can be filtered by,
+// including the legacy ones.
+func queryableOutcomes() []string {
+	var legacy []string
+	for outcome := range legacyOutcomes {
+		legacy = append(legacy, outcome)
+	}
+	sort.Strings(legacy)
+	return append(append([]string{}, outcomes...), legacy...)
+}
+
+func parseHistoryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func acceptsOnlyText(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "text/plain") && !strings.Contains(accept, "application/json")
+}
+
+func writeHistoryText(w http.ResponseWriter, page historyPage) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for i, r := range page.Results {
+		fmt.Fprintf(w, "%d. %v\n\n", i+1, r.metric())
+	}
+	if page.NextCursor != "" {
+		fmt.Fprintf(w, "More results with cursor=%s\n", page.NextCursor)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(jsonError{message})
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryHandlerFiltersResults(t *testing.T) {
+	handler := historyHandler(newHistoryHandlerTestHistory())
+
+	page := getHistoryPage(t, handler, "/__history?uuid=uuid1&environment=env1&outcome=missing")
+
+	require.Len(t, page.Results, 1)
+	assert.Equal(t, "uuid1", page.Results[0].UUID)
+	assert.Equal(t, "env1", page.Results[0].Environment)
+	assert.False(t, page.Results[0].PublishOK)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestHistoryHandlerAcceptsLegacyOutcomes(t *testing.T) {
+	handler := historyHandler(newHistoryHandlerTestHistory())
+
+	page := getHistoryPage(t, handler, "/__history?environment=env1&outcome=success")
+
+	require.Len(t, page.Results, 2)
+	assert.Equal(t, "uuid3", page.Results[0].UUID)
+	assert.Equal(t, outcomeOnTime, page.Results[0].Outcome)
+	assert.Equal(t, "uuid2", page.Results[1].UUID)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/__history?outcome=maybe", nil))
+	assert.Contains(t, w.Body.String(), "failure, success", "the legacy outcomes should be listed as valid")
+}
+
+func TestHistoryHandlerAcceptsPlatformAsEnvironment(t *testing.T) {
+	handler := historyHandler(newHistoryHandlerTestHistory())
+
+	page := getHistoryPage(t, handler, "/__history?platform=env2")
+
+	require.Len(t, page.Results, 1)
+	assert.Equal(t, "env2", page.Results[0].Environment)
+}
+
+func TestHistoryHandlerPaginates(t *testing.T) {
+	handler := historyHandler(newHistoryHandlerTestHistory())
+
+	page := getHistoryPage(t, handler, "/__history?alias=content&limit=2")
+	require.Len(t, page.Results, 2)
+	assert.Equal(t, "uuid3", page.Results[0].UUID)
+	assert.Equal(t, "uuid2", page.Results[1].UUID)
+	require.NotEmpty(t, page.NextCursor)
+
+	page = getHistoryPage(t, handler, "/__history?alias=content&limit=2&cursor="+page.NextCursor)
+	require.Len(t, page.Results, 2)
+	assert.Equal(t, "uuid1", page.Results[0].UUID)
+	assert.Equal(t, "uuid1", page.Results[1].UUID)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestHistoryHandlerFiltersByTimeRange(t *testing.T) {
+	handler := historyHandler(newHistoryHandlerTestHistory())
+
+	page := getHistoryPage(t, handler, "/__history?from=2018-01-01T10:00:00Z&to=2018-01-01T11:00:00Z")
+
+	require.Len(t, page.Results, 1)
+	assert.Equal(t, "uuid3", page.Results[0].UUID)
+}
+
+func TestHistoryHandlerRejectsInvalidParameters(t *testing.T) {
+	handler := historyHandler(newHistoryHandlerTestHistory())
+
+	for _, query := range []string{"outcome=maybe", "from=yesterday", "to=1234", "limit=0", "limit=abc", "limit=501", "cursor=abc"} {
+		req := httptest.NewRequest("GET", "/__history?"+query, nil)
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.Contains(t, w.Body.String(), "invalid", query)
+	}
+}
+
+func TestHistoryHandlerServesTextToTextClients(t *testing.T) {
+	handler := historyHandler(newHistoryHandlerTestHistory())
+
+	req := httptest.NewRequest("GET", "/__history?uuid=uuid3", nil)
+	req.Header.Set("Accept", "text/plain")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "1. Tid: tid_3, UUID: uuid3, Platform: env1, Endpoint: content"), w.Body.String())
+}
+
+func newHistoryHandlerTestHistory() PublishHistory {
+	history := newMemoryHistory(time.Hour * 24 * 365 * 100)
+	t0, _ := time.Parse(time.RFC3339, "2018-01-01T09:30:00Z")
+	history.Add(newHistoryTestMetric("uuid1", "tid_1", "env1", "content", t0, false))
+	history.Add(newHistoryTestMetric("uuid1", "tid_1", "env2", "content", t0, true))
+	history.Add(newHistoryTestMetric("uuid2", "tid_2", "env1", "content", t0, true))
+	history.Add(newHistoryTestMetric("uuid3", "tid_3", "env1", "content", t0.Add(time.Hour), true))
+	return history
+}
+
+func getHistoryPage(t *testing.T, handler func(w http.ResponseWriter, r *http.Request), url string) historyPage {
+	req := httptest.NewRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var page historyPage
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+	return page
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryHistoryFilters(t *testing.T) {
+	history := newMemoryHistory(time.Hour)
+	t0 := time.Now()
+	history.Add(newHistoryTestMetric("uuid1", "tid_1", "env1", "content", t0, true))
+	history.Add(newHistoryTestMetric("uuid1", "tid_1", "env2", "content", t0, false))
+	history.Add(newHistoryTestMetric("uuid1", "tid_1", "env1", "notifications", t0, true))
+	history.Add(newHistoryTestMetric("uuid2", "tid_2", "env1", "content", t0.Add(-time.Hour), true))
+
+	var testCases = []struct {
+		query    HistoryQuery
+		expected int
+	}{
+		{HistoryQuery{}, 4},
+		{HistoryQuery{UUID: "uuid1"}, 3},
+		{HistoryQuery{TransactionID: "tid_2"}, 1},
+		{HistoryQuery{Environment: "env1"}, 3},
+		{HistoryQuery{Alias: "content"}, 3},
+		{HistoryQuery{UUID: "uuid1", Environment: "env1", Alias: "content"}, 1},
+		{HistoryQuery{UUID: "uuid3"}, 0},
+		{HistoryQuery{Outcome: outcomeMissing}, 1},
+		{HistoryQuery{Outcome: outcomeOnTime}, 3},
+		{HistoryQuery{From: t0.Add(-time.Minute), To: t0.Add(time.Minute)}, 3},
+		{HistoryQuery{From: t0}, 3},
+		{HistoryQuery{To: t0}, 1},
+		{HistoryQuery{Before: 3}, 2},
+		{HistoryQuery{Limit: 2}, 2},
+	}
+
+	for _, tc := range testCases {
+		actual, err := history.Query(tc.query)
+		assert.NoError(t, err)
+		assert.Len(t, actual, tc.expected, "query %+v", tc.query)
+	}
+}
+
+func TestQueryHistoryReturnsMostRecentFirst(t *testing.T) {
+	history := newMemoryHistory(time.Hour)
+	t0 := time.Now()
+	history.Add(newHistoryTestMetric("uuid1", "tid_1", "env1", "content", t0, true))
+	history.Add(newHistoryTestMetric("uuid2", "tid_2", "env1", "content", t0, true))
+
+	actual, err := history.Query(HistoryQuery{Limit: 1})
+	assert.NoError(t, err)
+	require.Len(t, actual, 1)
+	assert.Equal(t, "uuid2", actual[0].UUID)
+}
+
+func TestFileHistorySurvivesRestart(t *testing.T) {
+	path := tempHistoryPath(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	t0 := time.Now().Round(time.Millisecond)
+	history, err := newFileHistory(path, time.Hour)
+	require.NoError(t, err)
+	pm := newHistoryTestMetric("uuid1", "tid_1", "env1", "content", t0, true)
+	pm.publishInterval = Interval{3, 6}
+	history.Add(pm)
+	history.Add(newHistoryTestMetric("uuid2", "tid_2", "env2", "S3", t0, false))
+	require.NoError(t, history.Close())
+
+	history, err = newFileHistory(path, time.Hour)
+	require.NoError(t, err)
+	defer history.Close()
+	history.Add(newHistoryTestMetric("uuid3", "tid_3", "env1", "content", t0, true))
+
+	actual, err := history.Query(HistoryQuery{})
+	assert.NoError(t, err)
+	require.Len(t, actual, 3)
+	assert.Equal(t, "uuid3", actual[0].UUID)
+	assert.Equal(t, "uuid2", actual[1].UUID)
+	assert.False(t, actual[1].PublishOK)
+	assert.Equal(t, "S3", actual[1].Alias)
+
+	pm = actual[2].metric()
+	assert.Equal(t, "uuid1", pm.UUID)
+	assert.True(t, pm.publishOK)
+	assert.Equal(t, "tid_1", pm.tid)
+	assert.Equal(t, "env1", pm.platform)
+	assert.Equal(t, "content", pm.config.Alias)
+	assert.Equal(t, Interval{3, 6}, pm.publishInterval)
+	assert.Equal(t, "http://env1.example.org/content/", pm.endpoint.String())
+	assert.True(t, t0.Equal(pm.publishDate))
+}
+
+func TestFileHistoryDropsExpiredResultsOnLoad(t *testing.T) {
+	path := tempHistoryPath(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	history, err := newFileHistory(path, time.Hour)
+	require.NoError(t, err)
+	history.Add(newHistoryTestMetric("uuid1", "tid_1", "env1", "content", time.Now().Add(-2*time.Hour), true))
+	history.Add(newHistoryTestMetric("uuid2", "tid_2", "env1", "content", time.Now(), true))
+	require.NoError(t, history.Close())
+
+	history, err = newFileHistory(path, time.Hour)
+	require.NoError(t, err)
+	defer history.Close()
+
+	actual, err := history.Query(HistoryQuery{})
+	assert.NoError(t, err)
+	require.Len(t, actual, 1)
+	assert.Equal(t, "uuid2", actual[0].UUID)
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "uuid1", "expired results should be compacted out of the file")
+}
+
+func TestFileHistorySkipsUnreadableLines(t *testing.T) {
+	path := tempHistoryPath(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	line := `{"id":7,"uuid":"uuid1","publishDate":"` + time.Now().Format(time.RFC3339Nano) + `"}`
+	require.NoError(t, ioutil.WriteFile(path, []byte("not json\n"+line+"\n"), 0644))
+
+	history, err := newFileHistory(path, time.Hour)
+	require.NoError(t, err)
+	defer history.Close()
+	history.Add(newHistoryTestMetric("uuid2", "tid_2", "env1", "content", time.Now(), true))
+
+	actual, err := history.Query(HistoryQuery{})
+	assert.NoError(t, err)
+	require.Len(t, actual, 2)
+	assert.Equal(t, "uuid2", actual[0].UUID)
+	assert.Equal(t, uint64(8), history.lastID, "ids should continue from the stored ones")
+}
+
+func TestFileHistoryConvertsLegacyOutcomes(t *testing.T) {
+	path := tempHistoryPath(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	publishDate := time.Now().Format(time.RFC3339Nano)
+	lines := `{"id":1,"uuid":"uuid1","publishDate":"` + publishDate + `","outcome":"success","publishOk":true}` + "\n" +
+		`{"id":2,"uuid":"uuid2","publishDate":"` + publishDate + `","outcome":"failure"}` + "\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(lines), 0644))
+
+	history, err := newFileHistory(path, time.Hour)
+	require.NoError(t, err)
+	defer history.Close()
+
+	actual, _ := history.Query(HistoryQuery{Outcome: outcomeMissing})
+	require.Len(t, actual, 1)
+	assert.Equal(t, "uuid2", actual[0].UUID)
+	assert.Equal(t, outcomeMissing, actual[0].Outcome)
+	actual, _ = history.Query(HistoryQuery{Outcome: outcomeOnTime})
+	require.Len(t, actual, 1)
+	assert.Equal(t, "uuid1", actual[0].UUID)
+}
+
+func TestUnsupportedHistoryStore(t *testing.T) {
+	_, err := NewPublishHistory(HistoryConfig{Store: "cassandra"})
+	assert.Error(t, err)
+}
+
+func newHistoryTestMetric(uuid string, tid string, env string, alias string, publishDate time.Time, publishOK bool) PublishMetric {
+	endpoint, _ := url.Parse("http://" + env + ".example.org/" + alias + "/")
+	return PublishMetric{
+		UUID:        uuid,
+		publishOK:   publishOK,
+		publishDate: publishDate,
+		platform:    env,
+		config:      MetricConfig{Alias: alias},
+		endpoint:    *endpoint,
+		tid:         tid,
+	}
+}
+
+func tempHistoryPath(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "pam-history")
+	require.NoError(t, err)
+	return filepath.Join(dir, "history.db")
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// jsonLinesFile is the file of a store which survives restarts: the changes
+// are appended to it as JSON lines, and it is compacted by rewriting it with
+// the current values of the store.
+type jsonLinesFile struct {
+	path  string
+	kind  string //what the file holds, for the messages, ex. "history"
+	file  *os.File
+	lines int //lines appended since the file was opened or compacted
+}
+
+// load passes every line of the file to decode, which tells whether the line
+// could be read, and returns the number of lines which could not.
+// A missing file has no lines.
+func (f *jsonLinesFile) load(decode func(line []byte) bool) (int, error) {
+	file, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cannot open %s file [%s]: %v", f.kind, f.path, err)
+	}
+	defer file.Close()
+
+	skipped := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if !decode(scanner.Bytes()) {
+			skipped++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("cannot read %s file [%s]: %v", f.kind, f.path, err)
+	}
+
+	if skipped > 0 {
+		log.Warnf("Skipped [%d] unreadable lines in %s file [%s]", skipped, f.kind, f.path)
+	}
+	return skipped, nil
+}
+
+// open opens the file for appending.
+func (f *jsonLinesFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open %s file [%s]: %v", f.kind, f.path, err)
+	}
+	f.file = file
+	return nil
+}
+
+// append writes v as a line at the end of the file.
+func (f *jsonLinesFile) append(v interface{}) error {
+	if f.file == nil {
+		return fmt.Errorf("%s file [%s] is closed", f.kind, f.path)
+	}
+
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err = f.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	f.lines++
+	return nil
+}
+
+// compact replaces the file with the values passed to write by values, and
+// reopens it for appending.
+func (f *jsonLinesFile) compact(values func(write func(v interface{}) error) error) error {
+	tmpPath := f.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot create %s file [%s]: %v", f.kind, tmpPath, err)
+	}
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	lines := 0
+	err = values(func(v interface{}) error {
+		lines++
+		return enc.Encode(v)
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("cannot write %s file [%s]: %v", f.kind, tmpPath, err)
+	}
+
+	f.close()
+	if err = os.Rename(tmpPath, f.path); err != nil {
+		return fmt.Errorf("cannot replace %s file [%s]: %v", f.kind, f.path, err)
+	}
+	if err = f.open(); err != nil {
+		return err
+	}
+	f.lines = lines
+	return nil
+}
+
+func (f *jsonLinesFile) close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
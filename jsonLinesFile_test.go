@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLinesFileCompactsToTheValuesWritten(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pam-jsonlines")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	f := &jsonLinesFile{path: filepath.Join(dir, "values.db"), kind: "test"}
+	require.NoError(t, f.open())
+	for _, v := range []int{1, 2, 3} {
+		require.NoError(t, f.append(v))
+	}
+	assert.Equal(t, 3, f.lines)
+
+	err = f.compact(func(write func(v interface{}) error) error {
+		return write(3)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.lines)
+	require.NoError(t, f.append(4))
+	require.NoError(t, f.close())
+
+	var values []int
+	skipped, err := f.load(func(line []byte) bool {
+		var v int
+		if json.Unmarshal(line, &v) != nil {
+			return false
+		}
+		values = append(values, v)
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, skipped)
+	assert.Equal(t, []int{3, 4}, values)
+}
+
+func TestJSONLinesFileCountsTheUnreadableLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pam-jsonlines")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "values.db")
+	require.NoError(t, ioutil.WriteFile(path, []byte("1\nnot json\n2\n"), 0644))
+
+	f := &jsonLinesFile{path: path, kind: "test"}
+	skipped, err := f.load(func(line []byte) bool {
+		var v int
+		return json.Unmarshal(line, &v) == nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+}
@@ -0,0 +1,115 @@
+// Package jsonpath looks up the values of JSON documents by paths like "$.identifiers[*].value".
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path is a path to the values of a JSON document, made of the keys of
+// objects and the indexes of arrays, where -1 stands for all the elements.
+type Path []interface{}
+
+const allElements = -1
+
+// Parse parses paths like "$.identifiers[*].value" or "title", the
+// leading "$." being optional. The empty path is parsed as nil.
+func Parse(path string) (Path, error) {
+	if path == "" {
+		return nil, nil
+	}
+	p := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if p == "" {
+		return nil, fmt.Errorf("JSON path [%s] has no key", path)
+	}
+
+	var parsed Path
+	for _, segment := range strings.Split(p, ".") {
+		key := segment
+		indexes := ""
+		if i := strings.Index(segment, "["); i >= 0 {
+			key, indexes = segment[:i], segment[i:]
+		}
+		if key == "" && indexes == "" {
+			return nil, fmt.Errorf("JSON path [%s] has an empty key", path)
+		}
+		if key != "" {
+			parsed = append(parsed, key)
+		}
+
+		for indexes != "" {
+			end := strings.Index(indexes, "]")
+			if !strings.HasPrefix(indexes, "[") || end < 0 {
+				return nil, fmt.Errorf("JSON path [%s] has an invalid index", path)
+			}
+			index := allElements
+			if indexes[1:end] != "*" {
+				var err error
+				if index, err = strconv.Atoi(indexes[1:end]); err != nil || index < 0 {
+					return nil, fmt.Errorf("JSON path [%s] has an invalid index [%s]", path, indexes[1:end])
+				}
+			}
+			parsed = append(parsed, index)
+			indexes = indexes[end+1:]
+		}
+	}
+	return parsed, nil
+}
+
+// Lookup returns the values at the path in doc, a document unmarshalled from JSON.
+func (p Path) Lookup(doc interface{}) []interface{} {
+	values := []interface{}{doc}
+	for _, segment := range p {
+		var next []interface{}
+		for _, value := range values {
+			switch s := segment.(type) {
+			case string:
+				if object, ok := value.(map[string]interface{}); ok {
+					if v, found := object[s]; found {
+						next = append(next, v)
+					}
+				}
+			case int:
+				array, ok := value.([]interface{})
+				if !ok {
+					continue
+				}
+				if s == allElements {
+					next = append(next, array...)
+				} else if s < len(array) {
+					next = append(next, array[s])
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// Matches tells whether a string value at the path in doc satisfies match.
+func (p Path) Matches(doc interface{}, match func(string) bool) bool {
+	for _, v := range p.Lookup(doc) {
+		if s, ok := v.(string); ok && match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Path) String() string {
+	path := "$"
+	for _, segment := range p {
+		switch s := segment.(type) {
+		case string:
+			path += "." + s
+		case int:
+			if s == allElements {
+				path += "[*]"
+			} else {
+				path += "[" + strconv.Itoa(s) + "]"
+			}
+		}
+	}
+	return path
+}
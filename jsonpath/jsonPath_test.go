@@ -0,0 +1,33 @@
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		path     string
+		expected string
+		valid    bool
+	}{
+		{"publishReference", "$.publishReference", true},
+		{"$.identifiers[*].value", "$.identifiers[*].value", true},
+		{"$.items[0][1]", "$.items[0][1]", true},
+		{"$", "", false},
+		{"$.a..b", "", false},
+		{"$.items[x]", "", false},
+		{"$.items[0", "", false},
+	}
+
+	for _, test := range tests {
+		p, err := Parse(test.path)
+		if test.valid {
+			assert.NoError(t, err, test.path)
+			assert.Equal(t, test.expected, p.String())
+		} else {
+			assert.Error(t, err, test.path)
+		}
+	}
+}
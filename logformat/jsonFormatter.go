@@ -0,0 +1,87 @@
+package logformat
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	levelKey     = "level"
+	timestampKey = "timestamp"
+	locationKey  = "location"
+	msgKey       = "msg"
+)
+
+// JSONFormatter formats each entry as a JSON object on one line, with its level,
+// timestamp, code location, transaction_id and message, and its fields as typed
+// properties, ex. {"level":"INFO","timestamp":"2017-03-09T14:20:31.337Z","location":"scheduler.go:152","msg":"Checking.","uuid":"...","attempt":2}
+type JSONFormatter struct {
+	stackPattern  *regexp.Regexp
+	vendorPattern *regexp.Regexp
+}
+
+func NewJSONFormatter(pattern string) *JSONFormatter {
+	f := JSONFormatter{}
+	if pattern != "" {
+		f.stackPattern = regexp.MustCompile(pattern)
+		f.vendorPattern = regexp.MustCompile(pattern + "vendor/.*")
+	}
+	return &f
+}
+
+func (f *JSONFormatter) Format(entry *log.Entry) ([]byte, error) {
+	data := make(map[string]interface{}, len(entry.Data)+5)
+	for k, v := range entry.Data {
+		switch k {
+		case levelKey, timestampKey, locationKey, msgKey:
+			// fields cannot replace the properties of the entry
+			k = "fields." + k
+		}
+		data[k] = jsonValue(v)
+	}
+
+	level := strings.ToUpper(entry.Level.String())
+	if entry.Level == log.WarnLevel {
+		level = "WARN"
+	}
+	data[levelKey] = level
+	data[timestampKey] = entry.Time.UTC().Format(time.RFC3339Nano)
+	if location := f.findCodeLocation(); location != "" {
+		data[locationKey] = location
+	}
+	data[msgKey] = entry.Message
+
+	serialized, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the log entry to JSON: %v", err)
+	}
+	return append(serialized, '\n'), nil
+}
+
+func (f *JSONFormatter) findCodeLocation() string {
+	if f.stackPattern == nil {
+		return ""
+	}
+
+	file, lineNum, ok := findCaller(f.stackPattern, f.vendorPattern)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%v", file, lineNum)
+}
+
+// jsonValue keeps the values which can be marshalled to JSON, and formats the others as strings.
+func jsonValue(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return v
+}
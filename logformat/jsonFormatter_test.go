@@ -0,0 +1,68 @@
+package logformat
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLogging(t *testing.T) {
+	f := NewJSONFormatter(`.*/github\.com/Financial-Times/.*`)
+
+	now := time.Now()
+	logEntry := log.Entry{
+		Data:    log.Fields{"transaction_id": "tx_test123", "uuid": "1234-1234", "attempt": 2, "late": true},
+		Time:    now,
+		Level:   log.WarnLevel,
+		Message: "Uh-oh!",
+	}
+
+	b, e := f.Format(&logEntry)
+
+	assert.NoError(t, e, "no error expected")
+	actual := string(b)
+	assert.True(t, strings.HasSuffix(actual, "}\n"), "formatted entry should be on one line")
+	assert.Equal(t, 1, strings.Count(actual, "\n"), "formatted entry should be on one line")
+
+	var logged map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &logged))
+	assert.Equal(t, "WARN", logged["level"])
+	assert.Equal(t, now.UTC().Format(time.RFC3339Nano), logged["timestamp"])
+	assert.Equal(t, "Uh-oh!", logged["msg"])
+	assert.Equal(t, "tx_test123", logged["transaction_id"])
+	assert.Equal(t, "1234-1234", logged["uuid"])
+	assert.Equal(t, float64(2), logged["attempt"], "fields should keep their JSON type")
+	assert.Equal(t, true, logged["late"], "fields should keep their JSON type")
+	assert.Regexp(t, regexp.MustCompile(`^jsonFormatter_test\.go:\d+$`), logged["location"], "formatted entry should contain code location")
+}
+
+func TestJSONLoggingOfSpecialFields(t *testing.T) {
+	f := NewJSONFormatter("")
+
+	logEntry := log.Entry{
+		Data:    log.Fields{"error": errors.New("boom"), "msg": "not the message", "callback": func() {}},
+		Time:    time.Now(),
+		Level:   log.ErrorLevel,
+		Message: "Terrible!",
+	}
+
+	b, e := f.Format(&logEntry)
+
+	assert.NoError(t, e, "no error expected")
+	var logged map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &logged))
+	assert.Equal(t, "ERROR", logged["level"])
+	assert.Equal(t, "Terrible!", logged["msg"], "fields should not replace the message")
+	assert.Equal(t, "not the message", logged["fields.msg"])
+	assert.Equal(t, "boom", logged["error"], "errors should be logged by their message")
+	assert.IsType(t, "", logged["callback"], "values which are not JSON should be logged as strings")
+	assert.NotContains(t, logged, "location", "no code location is logged without a pattern")
+	assert.NotContains(t, logged, "transaction_id")
+}
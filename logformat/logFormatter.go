@@ -59,17 +59,25 @@ func (f *SLF4JFormatter) findCodeLocation() string {
 		return ""
 	}
 
-	// start at 2 because we know 0 and 1 are within this file
-	for i := 2; i < 10; i++ {
+	file, lineNum, ok := findCaller(f.stackPattern, f.vendorPattern)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%v:", file, lineNum)
+}
+
+// findCaller returns the file name and line of the first stack entry that matches
+// the specified pattern, excluding vendored code (which likely matches the pattern regardless)
+func findCaller(stackPattern *regexp.Regexp, vendorPattern *regexp.Regexp) (string, int, bool) {
+	// start at 3 because we know 0 to 2 are within this package
+	for i := 3; i < 12; i++ {
 		_, file, lineNum, ok := runtime.Caller(i)
-		// find stack entry that matches the specified pattern
-		// but exclude vendored code (which likely matches the pattern regardless)
-		if ok && f.stackPattern.MatchString(file) && !f.vendorPattern.MatchString(file) {
-			return fmt.Sprintf("%s:%v:", file[strings.LastIndex(file, "/")+1:], lineNum)
+		if ok && stackPattern.MatchString(file) && !vendorPattern.MatchString(file) {
+			return file[strings.LastIndex(file, "/")+1:], lineNum, true
 		}
 	}
 
-	return "unknown"
+	return "", 0, false
 }
 
 func (f *SLF4JFormatter) findTransactionId(data map[string]interface{}) string {
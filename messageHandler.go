@@ -1,15 +1,16 @@
 package main
 
 import (
-	"encoding/json"
-	"encoding/xml"
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/Financial-Times/message-queue-gonsumer/consumer"
 	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/Financial-Times/publish-availability-monitor/tracing"
 	log "github.com/Sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const systemIDKey = "Origin-System-Id"
@@ -35,24 +36,37 @@ func (h *kafkaMessageHandler) HandleMessage(msg consumer.Message) {
 		return
 	}
 
+	// the publish is traced from the CMS when the message carries the trace
+	ctx, span := tracing.Start(tracing.Extract(context.Background(), msg.Headers), "HandleMessage")
+	defer span.End()
+	span.SetAttributes(attribute.String("transaction_id", tid), attribute.String("systemId", msg.Headers[systemIDKey]))
+
 	publishDateString := msg.Headers["Message-Timestamp"]
 	publishDate, err := time.Parse(dateLayout, publishDateString)
 	if err != nil {
 		log.Errorf("Cannot parse publish date [%v] from message [%v], error: [%v]",
 			publishDateString, tid, err.Error())
+		tracing.SetError(span, err)
 		return
 	}
 
-	publishedContent, err := h.unmarshalContent(msg)
+	publishedContent, err := h.unmarshalContent(ctx, msg)
 	if err != nil {
 		log.Warnf("Cannot unmarshal message [%v], error: [%v]", tid, err.Error())
+		tracing.SetError(span, err)
 		return
 	}
+	span.SetAttributes(attribute.String("uuid", publishedContent.GetUUID()), attribute.String("contentType", publishedContent.GetType()))
+
+	monitoringType := publishedContent.GetType()
+	if contentTypeRouter != nil {
+		monitoringType = contentTypeRouter.monitoringType(publishedContent, msg)
+	}
 
 	var paramsToSchedule []*schedulerParam
 
 	for _, preCheck := range mainPreChecks() {
-		ok, scheduleParam := preCheck(publishedContent, tid, publishDate)
+		ok, scheduleParam := preCheck(ctx, publishedContent, monitoringType, tid, publishDate)
 		if ok {
 			paramsToSchedule = append(paramsToSchedule, scheduleParam)
 		} else {
@@ -62,15 +76,17 @@ func (h *kafkaMessageHandler) HandleMessage(msg consumer.Message) {
 	}
 
 	for _, preCheck := range additionalPreChecks() {
-		ok, scheduleParam := preCheck(publishedContent, tid, publishDate)
+		ok, scheduleParam := preCheck(ctx, publishedContent, monitoringType, tid, publishDate)
 		if ok {
 			paramsToSchedule = append(paramsToSchedule, scheduleParam)
 		}
 	}
 
+	scheduled := 0
 	for _, scheduleParam := range paramsToSchedule {
-		scheduleChecks(scheduleParam)
+		scheduled += scheduleChecks(ctx, scheduleParam)
 	}
+	span.SetAttributes(attribute.Int("scheduledChecks", scheduled))
 }
 
 func (h *kafkaMessageHandler) isIgnorableMessage(tid string) bool {
@@ -86,44 +102,11 @@ func (h *kafkaMessageHandler) isContentCarouselTransactionID(tid string) bool {
 }
 
 // UnmarshalContent unmarshals the message body into the appropriate content type based on the systemID header.
-func (h *kafkaMessageHandler) unmarshalContent(msg consumer.Message) (content.Content, error) {
-	binaryContent := []byte(msg.Body)
-
-	headers := msg.Headers
-	systemID := headers[systemIDKey]
-	txID := msg.Headers["X-Request-Id"]
-	switch systemID {
-	case "http://cmdb.ft.com/systems/methode-web-pub":
-		var eomFile content.EomFile
-
-		err := json.Unmarshal(binaryContent, &eomFile)
-		if err != nil {
-			return nil, err
-		}
-		xml.Unmarshal([]byte(eomFile.Attributes), &eomFile.Source)
-		eomFile = eomFile.Initialize(binaryContent).(content.EomFile)
-		theType, resolvedUuid, err := h.typeRes.ResolveTypeAndUuid(eomFile, txID)
-		if err != nil {
-			return nil, fmt.Errorf("couldn't map kafka message to methode Content while fetching its type and uuid. %v", err)
-		}
-		eomFile.Type = theType
-		eomFile.UUID = resolvedUuid
-		return eomFile, nil
-	case "http://cmdb.ft.com/systems/wordpress":
-		var wordPressMsg content.WordPressMessage
-		err := json.Unmarshal(binaryContent, &wordPressMsg)
-		if err != nil {
-			return nil, err
-		}
-		return wordPressMsg.Initialize(binaryContent), nil
-	case "http://cmdb.ft.com/systems/next-video-editor":
-		var video content.Video
-		err := json.Unmarshal(binaryContent, &video)
-		if err != nil {
-			return nil, err
-		}
-		return video.Initialize(binaryContent), nil
-	default:
+func (h *kafkaMessageHandler) unmarshalContent(ctx context.Context, msg consumer.Message) (content.Content, error) {
+	systemID := msg.Headers[systemIDKey]
+	source, found := contentSources[systemID]
+	if !found {
 		return nil, fmt.Errorf("unsupported content with system ID: [%s]", systemID)
 	}
+	return source(ctx, h, msg)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"io/ioutil"
 	"testing"
 
@@ -59,11 +60,11 @@ func TestIsIgnorableMessage_carouselGeneratedMessage(t *testing.T) {
 
 func TestUnmarshalContent_ValidMessageMethodeSystemHeader_NoError(t *testing.T) {
 	typeRes := new(MockTypeResolver)
-	typeRes.On("ResolveTypeAndUuid", mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
+	typeRes.On("ResolveTypeAndUuid", mock.Anything, mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
 
 	h := kafkaMessageHandler{typeRes}
 
-	if _, err := h.unmarshalContent(validMethodeMessage); err != nil {
+	if _, err := h.unmarshalContent(context.Background(), validMethodeMessage); err != nil {
 		t.Errorf("Message with valid system ID [%s] cannot be unmarshalled!", validWordpressMessage.Headers["Origin-System-Id"])
 	}
 }
@@ -72,7 +73,7 @@ func TestUnmarshalContent_ValidMessageWordpressSystemHeader_NoError(t *testing.T
 	typeRes := new(MockTypeResolver)
 	h := kafkaMessageHandler{typeRes}
 
-	if _, err := h.unmarshalContent(validWordpressMessage); err != nil {
+	if _, err := h.unmarshalContent(context.Background(), validWordpressMessage); err != nil {
 		t.Errorf("Message with valid system ID [%s] cannot be unmarshalled!", validWordpressMessage.Headers["Origin-System-Id"])
 	}
 }
@@ -81,7 +82,7 @@ func TestUnmarshalContent_InvalidMessageMissingHeader_Error(t *testing.T) {
 	typeRes := new(MockTypeResolver)
 	h := kafkaMessageHandler{typeRes}
 
-	if _, err := h.unmarshalContent(invalidMessageWrongHeader); err == nil {
+	if _, err := h.unmarshalContent(context.Background(), invalidMessageWrongHeader); err == nil {
 		t.Error("Expected failure, but message with missing system ID successfully unmarshalled!")
 	}
 }
@@ -90,17 +91,17 @@ func TestUnmarshalContent_InvalidMessageWrongSystemId_Error(t *testing.T) {
 	typeRes := new(MockTypeResolver)
 	h := kafkaMessageHandler{typeRes}
 
-	if _, err := h.unmarshalContent(invalidMessageWrongSystemID); err == nil {
+	if _, err := h.unmarshalContent(context.Background(), invalidMessageWrongSystemID); err == nil {
 		t.Error("Expected failure, but message with wrong system ID successfully unmarshalled!")
 	}
 }
 
 func TestUnmarshalContent_InvalidMethodeContentWrongJSONFormat_Error(t *testing.T) {
 	typeRes := new(MockTypeResolver)
-	typeRes.On("ResolveTypeAndUuid", mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
+	typeRes.On("ResolveTypeAndUuid", mock.Anything, mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
 	h := kafkaMessageHandler{typeRes}
 
-	if _, err := h.unmarshalContent(invalidMethodeMessageWrongJSONFormat); err == nil {
+	if _, err := h.unmarshalContent(context.Background(), invalidMethodeMessageWrongJSONFormat); err == nil {
 		t.Error("Expected failure, but message with wrong JSON format successfully unmarshalled!")
 	}
 }
@@ -109,7 +110,7 @@ func TestUnmarshalContent_InvalidWordPressContentWrongJSONFormat_Error(t *testin
 	typeRes := new(MockTypeResolver)
 	h := kafkaMessageHandler{typeRes}
 
-	if _, err := h.unmarshalContent(invalidWordPressMessageWrongJSONFormat); err == nil {
+	if _, err := h.unmarshalContent(context.Background(), invalidWordPressMessageWrongJSONFormat); err == nil {
 		t.Error("Expected failure, but message with wrong system ID successfully unmarshalled!")
 	}
 }
@@ -118,7 +119,7 @@ func TestUnmarshalContent_ValidWordPressMessageWithTypeField_TypeIsCorrectlyUnma
 	typeRes := new(MockTypeResolver)
 	h := kafkaMessageHandler{typeRes}
 
-	resultContent, err := h.unmarshalContent(validWordPressMessageWithTypeField)
+	resultContent, err := h.unmarshalContent(context.Background(), validWordPressMessageWithTypeField)
 	if err != nil {
 		t.Errorf("Expected success, but error occured [%v]", err)
 		return
@@ -140,12 +141,12 @@ func TestUnmarshalContent_ValidVideoMessage(t *testing.T) {
 	h := kafkaMessageHandler{typeRes}
 
 	for _, testCase := range testCases {
-		resultContent, err := h.unmarshalContent(testCase.videoMessage)
+		resultContent, err := h.unmarshalContent(context.Background(), testCase.videoMessage)
 		if err != nil {
 			t.Errorf("Expected success, but error occured [%v]", err)
 			return
 		}
-		valRes := resultContent.Validate("", "", "", "")
+		valRes := resultContent.Validate(context.Background(), "", "", "", "")
 		assert.False(t, valRes.IsMarkedDeleted, "Expected published content.")
 	}
 }
@@ -154,12 +155,12 @@ func TestUnmarshalContent_ValidDeletedVideoMessage(t *testing.T) {
 	typeRes := new(MockTypeResolver)
 	h := kafkaMessageHandler{typeRes}
 
-	resultContent, err := h.unmarshalContent(validDeleteVideoMsg)
+	resultContent, err := h.unmarshalContent(context.Background(), validDeleteVideoMsg)
 	if err != nil {
 		t.Errorf("Expected success, but error occured [%v]", err)
 		return
 	}
-	valRes := resultContent.Validate("", "", "", "")
+	valRes := resultContent.Validate(context.Background(), "", "", "", "")
 	assert.True(t, valRes.IsMarkedDeleted, "Expected deleted content.")
 }
 
@@ -167,18 +168,18 @@ func TestUnmarshalContent_InvalidVideoMessage(t *testing.T) {
 	typeRes := new(MockTypeResolver)
 	h := kafkaMessageHandler{typeRes}
 
-	resultContent, err := h.unmarshalContent(invalidVideoMsg)
+	resultContent, err := h.unmarshalContent(context.Background(), invalidVideoMsg)
 	if err != nil {
 		t.Errorf("Expected success, but error occured [%v]", err)
 		return
 	}
-	valRes := resultContent.Validate("", "", "", "")
+	valRes := resultContent.Validate(context.Background(), "", "", "", "")
 	assert.False(t, valRes.IsValid, "Expected invalid content.")
 }
 
 func TestUnmarshalContent_ContentIsMethodeList_LinkedObjectsFieldIsMarshalled(t *testing.T) {
 	typeRes := new(MockTypeResolver)
-	typeRes.On("ResolveTypeAndUuid", mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
+	typeRes.On("ResolveTypeAndUuid", mock.Anything, mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
 	h := kafkaMessageHandler{typeRes}
 
 	var validMethodeListMessage = consumer.Message{
@@ -188,7 +189,7 @@ func TestUnmarshalContent_ContentIsMethodeList_LinkedObjectsFieldIsMarshalled(t
 		},
 		Body: string(loadBytesForFile(t, "content/testdata/methode_list.json")),
 	}
-	resultContent, err := h.unmarshalContent(validMethodeListMessage)
+	resultContent, err := h.unmarshalContent(context.Background(), validMethodeListMessage)
 	if err != nil {
 		t.Errorf("Expected success, but error occured [%v]", err)
 		return
@@ -204,7 +205,7 @@ func TestUnmarshalContent_ContentIsMethodeList_LinkedObjectsFieldIsMarshalled(t
 
 func TestUnmarshalContent_ContentIsMethodeArticle_LinkedObjectsFieldIsEmpty(t *testing.T) {
 	typeRes := new(MockTypeResolver)
-	typeRes.On("ResolveTypeAndUuid", mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
+	typeRes.On("ResolveTypeAndUuid", mock.Anything, mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
 	h := kafkaMessageHandler{typeRes}
 
 	var validMethodeListMessage = consumer.Message{
@@ -214,7 +215,7 @@ func TestUnmarshalContent_ContentIsMethodeArticle_LinkedObjectsFieldIsEmpty(t *t
 		},
 		Body: string(loadBytesForFile(t, "content/testdata/methode_article.json")),
 	}
-	resultContent, err := h.unmarshalContent(validMethodeListMessage)
+	resultContent, err := h.unmarshalContent(context.Background(), validMethodeListMessage)
 	if err != nil {
 		t.Errorf("Expected success, but error occured [%v]", err)
 		return
@@ -230,7 +231,7 @@ func TestUnmarshalContent_ContentIsMethodeArticle_LinkedObjectsFieldIsEmpty(t *t
 
 func TestUnmarshalContent_ContentIsMethodeList_EmptyLinkedObjectsFieldIsMarshalled(t *testing.T) {
 	typeRes := new(MockTypeResolver)
-	typeRes.On("ResolveTypeAndUuid", mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
+	typeRes.On("ResolveTypeAndUuid", mock.Anything, mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
 	h := kafkaMessageHandler{typeRes}
 
 	var validMethodeListMessage = consumer.Message{
@@ -240,7 +241,7 @@ func TestUnmarshalContent_ContentIsMethodeList_EmptyLinkedObjectsFieldIsMarshall
 		},
 		Body: string(loadBytesForFile(t, "content/testdata/methode_empty_list.json")),
 	}
-	resultContent, err := h.unmarshalContent(validMethodeListMessage)
+	resultContent, err := h.unmarshalContent(context.Background(), validMethodeListMessage)
 	if err != nil {
 		t.Errorf("Expected success, but error occured [%v]", err)
 		return
@@ -256,10 +257,10 @@ func TestUnmarshalContent_ContentIsMethodeList_EmptyLinkedObjectsFieldIsMarshall
 
 func TestUnmarshalContent_MethodeBinaryContentSet(t *testing.T) {
 	typeRes := new(MockTypeResolver)
-	typeRes.On("ResolveTypeAndUuid", mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
+	typeRes.On("ResolveTypeAndUuid", mock.Anything, mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
 	h := kafkaMessageHandler{typeRes}
 
-	resultContent, err := h.unmarshalContent(validMethodeMessage)
+	resultContent, err := h.unmarshalContent(context.Background(), validMethodeMessage)
 	assert.NoError(t, err)
 
 	eomFile, ok := resultContent.(content.EomFile)
@@ -272,7 +273,7 @@ func TestUnmarshalContent_VideoBinaryContentSet(t *testing.T) {
 	typeRes := new(MockTypeResolver)
 	h := kafkaMessageHandler{typeRes}
 
-	resultContent, err := h.unmarshalContent(validVideoMsg)
+	resultContent, err := h.unmarshalContent(context.Background(), validVideoMsg)
 	assert.NoError(t, err)
 
 	video, ok := resultContent.(content.Video)
@@ -283,10 +284,10 @@ func TestUnmarshalContent_VideoBinaryContentSet(t *testing.T) {
 
 func TestIsValidExternalCPH(t *testing.T) {
 	typeRes := new(MockTypeResolver)
-	typeRes.On("ResolveTypeAndUuid", mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory_External_CPH", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
+	typeRes.On("ResolveTypeAndUuid", mock.Anything, mock.MatchedBy(func(eomFile content.EomFile) bool { return true }), "tid_0123wxyz").Return("EOM::CompoundStory_External_CPH", "79e7f5ed-63c7-46b2-9767-736f8ae3a3f6", nil)
 	h := kafkaMessageHandler{typeRes}
 
-	_, err := h.unmarshalContent(validContentPlaceholder)
+	_, err := h.unmarshalContent(context.Background(), validContentPlaceholder)
 	if err != nil {
 		t.Error("Valid external CPH shouldn't throw error.")
 	}
@@ -396,8 +397,8 @@ type MockTypeResolver struct {
 	mock.Mock
 }
 
-func (m *MockTypeResolver) ResolveTypeAndUuid(eomFile content.EomFile, txID string) (string, string, error) {
-	args := m.Called(eomFile, txID)
+func (m *MockTypeResolver) ResolveTypeAndUuid(ctx context.Context, eomFile content.EomFile, txID string) (string, string, error) {
+	args := m.Called(ctx, eomFile, txID)
 	return args.String(0), args.String(1), args.Error(2)
 }
 
@@ -1,18 +1,31 @@
 package main
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 
 	"github.com/Financial-Times/publish-availability-monitor/checks"
 	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/Financial-Times/publish-availability-monitor/tracing"
 	log "github.com/Sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var blogCategories = []string{"blog", "webchat-live-blogs", "webchat-live-qa", "webchat-markets-live", "fastft"}
 
+// methodeTypeRules derive the type of Methode content from its source, and
+// from whether it is a placeholder of content whose UUID was resolved.
+var methodeTypeRules = []TypeRule{
+	{ContentType: "EOM::CompoundStory", SourceCode: "ContentPlaceholder", UUIDResolved: true, Type: "EOM::CompoundStory_Internal_CPH"},
+	{ContentType: "EOM::CompoundStory", SourceCode: "ContentPlaceholder", Type: "EOM::CompoundStory_External_CPH"},
+	{ContentType: "EOM::CompoundStory", SourceCode: "DynamicContent", Type: "EOM::CompoundStory_DynamicContent"},
+}
+
+var methodeTypeRouter = mustNewTypeRouter(methodeTypeRules)
+
 type typeResolver interface {
-	ResolveTypeAndUuid(eomFile content.EomFile, txID string) (string, string, error)
+	ResolveTypeAndUuid(ctx context.Context, eomFile content.EomFile, txID string) (string, string, error)
 }
 
 type methodeTypeResolver struct {
@@ -25,33 +38,33 @@ func NewMethodeTypeResolver(uuidResolver checks.UUIDResolver) *methodeTypeResolv
 	}
 }
 
-func (m *methodeTypeResolver) ResolveTypeAndUuid(eomFile content.EomFile, txID string) (string, string, error) {
+func (m *methodeTypeResolver) ResolveTypeAndUuid(ctx context.Context, eomFile content.EomFile, txID string) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "ResolveTypeAndUuid")
+	defer span.End()
+
 	contentType := eomFile.ContentType
 	contentSrc := eomFile.Source.SourceCode
 	if contentSrc == "ContentPlaceholder" && contentType == "EOM::CompoundStory" {
-		resolvedUUID, err := m.resolveUUID(eomFile, txID)
+		resolvedUUID, err := m.resolveUUID(ctx, eomFile, txID)
 		if err != nil {
+			tracing.SetError(span, err)
 			return "", "", err
 		}
 
-		theType := "EOM::CompoundStory_External_CPH"
 		cphUUID := eomFile.UUID
 		if resolvedUUID != "" {
-			theType = "EOM::CompoundStory_Internal_CPH"
 			cphUUID = resolvedUUID
 		}
+		theType := methodeTypeRouter.route(&messageFacts{content: eomFile, contentType: contentType, uuidResolved: resolvedUUID != ""})
 		log.Infof("For placeholder resolved tid=%v type=%v uuid=%v", txID, theType, cphUUID)
+		span.SetAttributes(attribute.String("contentType", theType), attribute.String("uuid", cphUUID))
 		return theType, cphUUID, nil
 	}
 
-	if contentSrc == "DynamicContent" && contentType == "EOM::CompoundStory" {
-		return "EOM::CompoundStory_DynamicContent", eomFile.UUID, nil
-	}
-
-	return eomFile.ContentType, eomFile.UUID, nil
+	return methodeTypeRouter.route(&messageFacts{content: eomFile, contentType: contentType}), eomFile.UUID, nil
 }
 
-func (m *methodeTypeResolver) resolveUUID(eomFile content.EomFile, txID string) (string, error) {
+func (m *methodeTypeResolver) resolveUUID(ctx context.Context, eomFile content.EomFile, txID string) (string, error) {
 	attributes, err := m.buildAttributes(eomFile.Attributes)
 	if err != nil {
 		return "", err
@@ -59,7 +72,7 @@ func (m *methodeTypeResolver) resolveUUID(eomFile content.EomFile, txID string)
 
 	var uuid string
 	if attributes.OriginalUUID != "" {
-		uuid, err = m.resolver.ResolveOriginalUUID(attributes.OriginalUUID, txID)
+		uuid, err = m.resolver.ResolveOriginalUUID(ctx, attributes.OriginalUUID, txID)
 		if err != nil {
 			return "", err
 		}
@@ -69,7 +82,7 @@ func (m *methodeTypeResolver) resolveUUID(eomFile content.EomFile, txID string)
 		}
 	} else {
 		if isBlogCategory(attributes) {
-			uuid, err = m.resolver.ResolveIdentifier(attributes.ServiceId, attributes.RefField, txID)
+			uuid, err = m.resolver.ResolveIdentifier(ctx, attributes.ServiceId, attributes.RefField, txID)
 			if err != nil {
 				return "", fmt.Errorf("couldn't resolve blog uuid, error was: %v", err)
 			}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -40,7 +41,7 @@ func TestResolveTypeAndUuid_NotCPH(t *testing.T) {
 
 	typeResolver := methodeTypeResolver{}
 
-	resultType, resultUUID, err := typeResolver.ResolveTypeAndUuid(eomFile, "tid_0123wxyz")
+	resultType, resultUUID, err := typeResolver.ResolveTypeAndUuid(context.Background(), eomFile, "tid_0123wxyz")
 
 	assert.NoError(t, err, "Normal methode article shouldn't throw error on type and uuid resolve.")
 	assert.Equal(t, "EOM::CompoundStory", resultType)
@@ -58,10 +59,10 @@ func TestResolveTypeAndUuid_InternalCPH_WithoutOriginalUUID(t *testing.T) {
 	}
 
 	resolverMock := new(MockUUIDResolver)
-	resolverMock.On("ResolveIdentifier", "http://ftalphaville.ft.com/?p=2194657", "2194657", "tid_0123wxyz").Return("f3dbacdf-9796-3331-b030-05082f0b8157", nil)
+	resolverMock.On("ResolveIdentifier", mock.Anything, "http://ftalphaville.ft.com/?p=2194657", "2194657", "tid_0123wxyz").Return("f3dbacdf-9796-3331-b030-05082f0b8157", nil)
 	typeResolver := methodeTypeResolver{resolver: resolverMock}
 
-	resultType, resultUUID, err := typeResolver.ResolveTypeAndUuid(eomFile, "tid_0123wxyz")
+	resultType, resultUUID, err := typeResolver.ResolveTypeAndUuid(context.Background(), eomFile, "tid_0123wxyz")
 
 	assert.NoError(t, err, "Internal CPH shouldn't throw error on type and uuid resolve.")
 	assert.Equal(t, "EOM::CompoundStory_Internal_CPH", resultType)
@@ -79,10 +80,10 @@ func TestResolveTypeAndUuid_InternalCPH_WithOriginalUUID(t *testing.T) {
 	}
 
 	resolverMock := new(MockUUIDResolver)
-	resolverMock.On("ResolveOriginalUUID", "f3dbacdf-9796-3331-b030-05082f0b8157", "tid_0123wxyz").Return("f3dbacdf-9796-3331-b030-05082f0b8157", nil)
+	resolverMock.On("ResolveOriginalUUID", mock.Anything, "f3dbacdf-9796-3331-b030-05082f0b8157", "tid_0123wxyz").Return("f3dbacdf-9796-3331-b030-05082f0b8157", nil)
 	typeResolver := methodeTypeResolver{resolver:resolverMock}
 
-	resultType, resultUUID, err := typeResolver.ResolveTypeAndUuid(eomFile, "tid_0123wxyz")
+	resultType, resultUUID, err := typeResolver.ResolveTypeAndUuid(context.Background(), eomFile, "tid_0123wxyz")
 
 	assert.NoError(t, err, "Internal CPH shouldn't throw error on type and uuid resolve.")
 	assert.Equal(t, "EOM::CompoundStory_Internal_CPH", resultType)
@@ -100,10 +101,10 @@ func TestResolveTypeAndUuid_InternalCPH_WithOriginalUUID_ResolverError(t *testin
 	}
 
 	resolverMock := new(MockUUIDResolver)
-	resolverMock.On("ResolveOriginalUUID", "f3dbacdf-9796-3331-b030-05082f0b8157", "tid_0123wxyz").Return("", errors.New("Resolver error"))
+	resolverMock.On("ResolveOriginalUUID", mock.Anything, "f3dbacdf-9796-3331-b030-05082f0b8157", "tid_0123wxyz").Return("", errors.New("Resolver error"))
 	typeResolver := methodeTypeResolver{resolver:resolverMock}
 
-	_, _, err := typeResolver.ResolveTypeAndUuid(eomFile, "tid_0123wxyz")
+	_, _, err := typeResolver.ResolveTypeAndUuid(context.Background(), eomFile, "tid_0123wxyz")
 
 	assert.Error(t, err, "Resolver error")
 }
@@ -119,10 +120,10 @@ func TestResolveTypeAndUuid_InternalCPH_WithOriginalUUID_MissingUUID(t *testing.
 	}
 
 	resolverMock := new(MockUUIDResolver)
-	resolverMock.On("ResolveOriginalUUID", "f3dbacdf-9796-3331-b030-05082f0b8157", "tid_0123wxyz").Return("", nil)
+	resolverMock.On("ResolveOriginalUUID", mock.Anything, "f3dbacdf-9796-3331-b030-05082f0b8157", "tid_0123wxyz").Return("", nil)
 	typeResolver := methodeTypeResolver{resolver:resolverMock}
 
-	_, _, err := typeResolver.ResolveTypeAndUuid(eomFile, "tid_0123wxyz")
+	_, _, err := typeResolver.ResolveTypeAndUuid(context.Background(), eomFile, "tid_0123wxyz")
 
 	assert.Error(t, err, "couldn't resolve CPH uuid for tid=tid_0123wxyz, OriginalUUID=f3dbacdf-9796-3331-b030-05082f0b8157 is not present in the database")
 }
@@ -139,7 +140,7 @@ func TestResolveTypeAndUuid_ExternalCPH(t *testing.T) {
 
 	typeResolver := methodeTypeResolver{}
 
-	resultType, resultUUID, err := typeResolver.ResolveTypeAndUuid(eomFile, "tid_0123wxyz")
+	resultType, resultUUID, err := typeResolver.ResolveTypeAndUuid(context.Background(), eomFile, "tid_0123wxyz")
 
 	assert.NoError(t, err, "Internal CPH shouldn't throw error on type and uuid resolve.")
 	assert.Equal(t, "EOM::CompoundStory_External_CPH", resultType)
@@ -157,10 +158,10 @@ func TestResolveTypeAndUuid_InternalCPH_FailResolve_ThrowsError(t *testing.T) {
 	}
 
 	resolverMock := new(MockUUIDResolver)
-	resolverMock.On("ResolveIdentifier", "http://ftalphaville.ft.com/?p=2194657", "2194657", "tid_0123wxyz").Return("", errors.New("Error calling UUID resolver"))
+	resolverMock.On("ResolveIdentifier", mock.Anything, "http://ftalphaville.ft.com/?p=2194657", "2194657", "tid_0123wxyz").Return("", errors.New("Error calling UUID resolver"))
 	typeResolver := methodeTypeResolver{resolver: resolverMock}
 
-	_, _, err := typeResolver.ResolveTypeAndUuid(eomFile, "tid_0123wxyz")
+	_, _, err := typeResolver.ResolveTypeAndUuid(context.Background(), eomFile, "tid_0123wxyz")
 	assert.Error(t, err, "Internal CPH should throw error on failing to resolve UUID.")
 }
 
@@ -176,7 +177,7 @@ func TestResolveTypeAndUuid_CPH_InvalidAttributes_ThrowsError(t *testing.T) {
 
 	typeResolver := methodeTypeResolver{}
 
-	_, _, err := typeResolver.ResolveTypeAndUuid(eomFile, "tid_0123wxyz")
+	_, _, err := typeResolver.ResolveTypeAndUuid(context.Background(), eomFile, "tid_0123wxyz")
 	assert.Error(t, err, "CPH should throw error on invalid attributes.")
 }
 
@@ -192,7 +193,7 @@ func TestResolveTypeAndUuid_DynamicContent(t *testing.T) {
 
 	typeResolver := methodeTypeResolver{}
 
-	resultType, resultUUID, err := typeResolver.ResolveTypeAndUuid(eomFile, "tid_0123wxyz")
+	resultType, resultUUID, err := typeResolver.ResolveTypeAndUuid(context.Background(), eomFile, "tid_0123wxyz")
 
 	assert.NoError(t, err, "Internal CPH shouldn't throw error on type and uuid resolve.")
 	assert.Equal(t, "EOM::CompoundStory_DynamicContent", resultType)
@@ -203,12 +204,12 @@ type MockUUIDResolver struct {
 	mock.Mock
 }
 
-func (m *MockUUIDResolver) ResolveIdentifier(serviceId, refField, tid string) (string, error) {
-	args := m.Called(serviceId, refField, tid)
+func (m *MockUUIDResolver) ResolveIdentifier(ctx context.Context, serviceId, refField, tid string) (string, error) {
+	args := m.Called(ctx, serviceId, refField, tid)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockUUIDResolver) ResolveOriginalUUID(uuid, tid string) (string, error) {
-	args := m.Called(uuid, tid)
+func (m *MockUUIDResolver) ResolveOriginalUUID(ctx context.Context, uuid, tid string) (string, error) {
+	args := m.Called(ctx, uuid, tid)
 	return args.String(0), args.Error(1)
 }
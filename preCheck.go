@@ -1,30 +1,36 @@
 package main
 
 import (
+	"context"
 	"strings"
 	"time"
 
 	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/Financial-Times/publish-availability-monitor/tracing"
 	"github.com/Financial-Times/uuid-utils-go"
 	log "github.com/Sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var uuidDeriver = uuidutils.NewUUIDDeriverWith(uuidutils.IMAGE_SET)
 
-func mainPreChecks() []func(publishedContent content.Content, tid string, publishDate time.Time) (bool, *schedulerParam) {
-	return []func(publishedContent content.Content, tid string, publishDate time.Time) (bool, *schedulerParam){
+func mainPreChecks() []func(ctx context.Context, publishedContent content.Content, monitoringType string, tid string, publishDate time.Time) (bool, *schedulerParam) {
+	return []func(ctx context.Context, publishedContent content.Content, monitoringType string, tid string, publishDate time.Time) (bool, *schedulerParam){
 		mainPreCheck,
 	}
 }
 
-func additionalPreChecks() []func(publishedContent content.Content, tid string, publishDate time.Time) (bool, *schedulerParam) {
-	return []func(publishedContent content.Content, tid string, publishDate time.Time) (bool, *schedulerParam){
+func additionalPreChecks() []func(ctx context.Context, publishedContent content.Content, monitoringType string, tid string, publishDate time.Time) (bool, *schedulerParam) {
+	return []func(ctx context.Context, publishedContent content.Content, monitoringType string, tid string, publishDate time.Time) (bool, *schedulerParam){
 		imagePreCheck,
 		internalComponentsPreCheck,
 	}
 }
 
-func mainPreCheck(publishedContent content.Content, tid string, publishDate time.Time) (bool, *schedulerParam) {
+func mainPreCheck(ctx context.Context, publishedContent content.Content, monitoringType string, tid string, publishDate time.Time) (bool, *schedulerParam) {
+	ctx, span := tracing.Start(ctx, "mainPreCheck")
+	defer span.End()
+
 	uuid := publishedContent.GetUUID()
 	validationEndpointKey := getValidationEndpointKey(publishedContent, tid, uuid)
 	var validationEndpoint string
@@ -36,7 +42,8 @@ func mainPreCheck(publishedContent content.Content, tid string, publishDate time
 		username, password = getValidationCredentials()
 	}
 
-	valRes := publishedContent.Validate(validationEndpoint, tid, username, password)
+	valRes := publishedContent.Validate(ctx, validationEndpoint, tid, username, password)
+	span.SetAttributes(attribute.Bool("valid", valRes.IsValid), attribute.Bool("markedDeleted", valRes.IsMarkedDeleted))
 	if !valRes.IsValid {
 		log.Infof("Message [%v] with UUID [%v] is INVALID, skipping...", tid, uuid)
 		return false, nil
@@ -44,20 +51,31 @@ func mainPreCheck(publishedContent content.Content, tid string, publishDate time
 
 	log.Infof("Message [%v] with UUID [%v] is VALID.", tid, uuid)
 
-	if isMessagePastPublishSLA(publishDate, appConfig.Threshold) {
+	if isMessagePastPublishSLA(publishDate, appConfig.maxThresholdFor(monitoringType)) {
 		log.Infof("Message [%v] with UUID [%v] is past publish SLA, skipping.", tid, uuid)
+		span.SetAttributes(attribute.Bool("pastSLA", true))
 		return false, nil
 	}
 
-	return true, &schedulerParam{publishedContent, publishDate, tid, valRes.IsMarkedDeleted, &metricContainer, environments}
+	return true, &schedulerParam{
+		contentToCheck:  publishedContent,
+		contentType:     monitoringType,
+		publishDate:     publishDate,
+		tid:             tid,
+		isMarkedDeleted: valRes.IsMarkedDeleted,
+		metricContainer: metricContainer,
+		environments:    environments,
+	}
 }
 
 // for images we need to check their corresponding image sets
 // the image sets don't have messages of their own so we need to create one
-func imagePreCheck(publishedContent content.Content, tid string, publishDate time.Time) (bool, *schedulerParam) {
+func imagePreCheck(ctx context.Context, publishedContent content.Content, monitoringType string, tid string, publishDate time.Time) (bool, *schedulerParam) {
 	if publishedContent.GetType() != "Image" {
 		return false, nil
 	}
+	_, span := tracing.Start(ctx, "imagePreCheck")
+	defer span.End()
 
 	eomFile, ok := publishedContent.(content.EomFile)
 	if !ok {
@@ -70,14 +88,22 @@ func imagePreCheck(publishedContent content.Content, tid string, publishDate tim
 		return false, nil
 	}
 
-	return true, &schedulerParam{imageSetEomFile, publishDate, tid, false, &metricContainer, environments}
+	return true, &schedulerParam{
+		contentToCheck:  imageSetEomFile,
+		publishDate:     publishDate,
+		tid:             tid,
+		metricContainer: metricContainer,
+		environments:    environments,
+	}
 }
 
 // if this is normal content, schedule checks for internal components also
-func internalComponentsPreCheck(publishedContent content.Content, tid string, publishDate time.Time) (bool, *schedulerParam) {
+func internalComponentsPreCheck(ctx context.Context, publishedContent content.Content, monitoringType string, tid string, publishDate time.Time) (bool, *schedulerParam) {
 	if publishedContent.GetType() != "EOM::CompoundStory" {
 		return false, nil
 	}
+	ctx, span := tracing.Start(ctx, "internalComponentsPreCheck")
+	defer span.End()
 
 	eomFileForInternalComponentsCheck, ok := publishedContent.(content.EomFile)
 	if !ok {
@@ -89,13 +115,21 @@ func internalComponentsPreCheck(publishedContent content.Content, tid string, pu
 	var internalComponentsValidationEndpoint = appConfig.ValidationEndpoints["InternalComponents"]
 	var usr, pass = getValidationCredentials()
 
-	icValRes := publishedContent.Validate(internalComponentsValidationEndpoint, tid, usr, pass)
+	icValRes := publishedContent.Validate(ctx, internalComponentsValidationEndpoint, tid, usr, pass)
+	span.SetAttributes(attribute.Bool("valid", icValRes.IsValid))
 	if !icValRes.IsValid {
 		log.Infof("Message [%v] with UUID [%v] has INVALID internal components, skipping internal components schedule check.", tid, publishedContent.GetUUID())
 		return false, nil
 	}
 
-	return true, &schedulerParam{eomFileForInternalComponentsCheck, publishDate, tid, icValRes.IsMarkedDeleted, &metricContainer, environments}
+	return true, &schedulerParam{
+		contentToCheck:  eomFileForInternalComponentsCheck,
+		publishDate:     publishDate,
+		tid:             tid,
+		isMarkedDeleted: icValRes.IsMarkedDeleted,
+		metricContainer: metricContainer,
+		environments:    environments,
+	}
 }
 
 func getValidationCredentials() (string, string) {
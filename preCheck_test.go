@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/Financial-Times/publish-availability-monitor/tracing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const threshold = 120
@@ -70,3 +75,42 @@ func TestGetValidationEndpointKey_Story(t *testing.T) {
 	validationEndpointKey := getValidationEndpointKey(supportedSourceCodeStory, testTid, testUuid)
 	assert.Equal(t, validationEndpointKey, "EOM::Story", "Didn't get expected validation url key for Stroy")
 }
+
+func TestMainPreCheckUsesTheSLAOfTheMonitoringType(t *testing.T) {
+	validator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer validator.Close()
+	defer func(saved *AppConfig) { appConfig = saved }(appConfig)
+	appConfig = &AppConfig{
+		Threshold:             threshold,
+		ContentTypeThresholds: map[string]int{"LongStory": 10 * threshold},
+		ValidationEndpoints:   map[string]string{"EOM::Story": validator.URL},
+	}
+	publishDate := time.Now().Add(-2 * threshold * time.Second)
+
+	ok, _ := mainPreCheck(context.Background(), supportedSourceCodeStory, supportedSourceCodeStory.GetType(), testTid, publishDate)
+	assert.False(t, ok, "the content is past the SLA of its type")
+
+	ok, p := mainPreCheck(context.Background(), supportedSourceCodeStory, "LongStory", testTid, publishDate)
+	require.True(t, ok, "the content is within the SLA of the type it is monitored as")
+	assert.Equal(t, "LongStory", p.monitoringType())
+}
+
+func TestMainPreCheckTracesTheValidationInTheSpanOfThePublish(t *testing.T) {
+	recorder := recordSpans(t)
+	validator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer validator.Close()
+	defer func(saved *AppConfig) { appConfig = saved }(appConfig)
+	appConfig = &AppConfig{Threshold: threshold, ValidationEndpoints: map[string]string{"EOM::Story": validator.URL}}
+
+	ctx, publishSpan := tracing.Start(context.Background(), "HandleMessage")
+	ok, _ := mainPreCheck(ctx, supportedSourceCodeStory, supportedSourceCodeStory.GetType(), testTid, time.Now())
+	publishSpan.End()
+
+	assert.True(t, ok)
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "HTTP POST", spans[0].Name())
+	assert.Equal(t, "mainPreCheck", spans[1].Name())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID(), "the validation should be a child of the pre-check")
+	assert.Equal(t, publishSpan.SpanContext().SpanID(), spans[1].Parent().SpanID(), "the pre-check should be a child of the span of the publish")
+}
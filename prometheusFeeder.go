@@ -0,0 +1,52 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	publishLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pam_publish_latency_seconds",
+		Help:    "Time from the publish until the content was available, in seconds, for on-time and late publishes.",
+		Buckets: []float64{5, 10, 20, 30, 45, 60, 90, 120, 180, 300, 600},
+	}, []string{"environment", "alias", "content_type"})
+	publishResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pam_publish_results_total",
+		Help: "Number of completed publish checks, by outcome.",
+	}, []string{"environment", "alias", "content_type", "outcome"})
+	checksInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pam_checks_in_flight",
+		Help: "Number of publish checks currently running.",
+	}, []string{"environment", "alias"})
+	publishFindings = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pam_publish_findings_total",
+		Help: "Number of quality issues found by the publish checks, ex. duplicate notifications.",
+	}, []string{"environment", "alias", "finding"})
+)
+
+// PrometheusFeeder implements MetricDestination interface to expose PublishMetrics
+// as Prometheus metrics, served on /metrics.
+type PrometheusFeeder struct{}
+
+// NewPrometheusFeeder returns a PrometheusFeeder.
+func NewPrometheusFeeder() *PrometheusFeeder {
+	return &PrometheusFeeder{}
+}
+
+// Send counts pm by its outcome and, for the content which became available,
+// records how long it took. Only the findings of the findings reports are counted.
+func (pf PrometheusFeeder) Send(pm PublishMetric) {
+	if !pm.findingsReport {
+		if pm.latency > 0 {
+			publishLatency.WithLabelValues(pm.platform, pm.config.Alias, pm.contentType).Observe(pm.latency.Seconds())
+		}
+		publishResults.WithLabelValues(pm.platform, pm.config.Alias, pm.contentType, outcomeOf(pm)).Inc()
+	}
+	for _, finding := range pm.findings {
+		publishFindings.WithLabelValues(pm.platform, pm.config.Alias, finding).Inc()
+	}
+}
+
+// Flush does nothing, as the PublishMetrics are counted when they are sent.
+func (pf PrometheusFeeder) Flush() {}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusFeederCountsOutcomes(t *testing.T) {
+	feeder := NewPrometheusFeeder()
+	successes := publishResults.WithLabelValues("prom-env", "content", "EOM::CompoundStory", outcomeOnTime)
+	failures := publishResults.WithLabelValues("prom-env", "content", "EOM::CompoundStory", outcomeMissing)
+	latency := publishLatency.WithLabelValues("prom-env", "content", "EOM::CompoundStory")
+	initialSuccesses, initialFailures, initialLatency := testutil.ToFloat64(successes), testutil.ToFloat64(failures), sampleCount(t, latency)
+
+	pm := newHistoryTestMetric("uuid1", "tid_1", "prom-env", "content", time.Now(), true)
+	pm.contentType = "EOM::CompoundStory"
+	pm.publishInterval = Interval{10, 20}
+	pm.latency = 15 * time.Second
+	feeder.Send(pm)
+	pm.publishOK = false
+	pm.latency = 0
+	feeder.Send(pm)
+
+	assert.Equal(t, initialSuccesses+1, testutil.ToFloat64(successes))
+	assert.Equal(t, initialFailures+1, testutil.ToFloat64(failures))
+	assert.Equal(t, initialLatency+1, sampleCount(t, latency), "only the publishes which became available should be observed")
+}
+
+func TestPrometheusFeederCountsFindings(t *testing.T) {
+	duplicates := publishFindings.WithLabelValues("prom-env", "notifications", findingDuplicateNotification)
+	initial := testutil.ToFloat64(duplicates)
+
+	pm := newHistoryTestMetric("uuid1", "tid_1", "prom-env", "notifications", time.Now(), true)
+	pm.findings = []string{findingDuplicateNotification}
+	NewPrometheusFeeder().Send(pm)
+
+	assert.Equal(t, initial+1, testutil.ToFloat64(duplicates))
+}
+
+func TestPrometheusFeederOnlyCountsTheFindingsOfAFindingsReport(t *testing.T) {
+	duplicates := publishFindings.WithLabelValues("prom-env", "notifications", findingDuplicateNotification)
+	successes := publishResults.WithLabelValues("prom-env", "notifications", "", outcomeOnTime)
+	initialDuplicates, initialSuccesses := testutil.ToFloat64(duplicates), testutil.ToFloat64(successes)
+
+	pm := newHistoryTestMetric("uuid1", "tid_1", "prom-env", "notifications", time.Now(), true)
+	pm.findings = []string{findingDuplicateNotification}
+	pm.findingsReport = true
+	NewPrometheusFeeder().Send(pm)
+
+	assert.Equal(t, initialDuplicates+1, testutil.ToFloat64(duplicates))
+	assert.Equal(t, initialSuccesses, testutil.ToFloat64(successes), "the result of the check was counted when it was sent")
+}
+
+func TestMetricsAreServed(t *testing.T) {
+	pm := newHistoryTestMetric("uuid1", "tid_1", "prom-env", "S3", time.Now(), true)
+	pm.latency = 5 * time.Second
+	NewPrometheusFeeder().Send(pm)
+
+	w := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Contains(t, w.Body.String(), `pam_publish_results_total{alias="S3",content_type="",environment="prom-env",outcome="on-time"}`)
+	assert.Contains(t, w.Body.String(), "# TYPE pam_publish_latency_seconds histogram")
+}
+
+// sampleCount returns the number of observations of a histogram.
+func sampleCount(t *testing.T, h prometheus.Observer) uint64 {
+	var m dto.Metric
+	require.NoError(t, h.(prometheus.Metric).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"io/ioutil"
@@ -10,6 +11,7 @@ import (
 	"github.com/Financial-Times/publish-availability-monitor/checks"
 	"github.com/Financial-Times/publish-availability-monitor/feeds"
 	log "github.com/Sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // PublishCheck performs an availability  check on a piece of content, at a
@@ -21,14 +23,49 @@ type PublishCheck struct {
 	username      string
 	password      string
 	Threshold     int
+	GraceWindow   int //seconds after the SLA during which the check continues, to tell late publishes from missing ones
 	CheckInterval int
 	ResultSink    chan PublishMetric
+	checkpointID  uint64            //0 when the check is not checkpointed
+	spanContext   trace.SpanContext //the span of the publish the check belongs to, not valid when the publish is not traced
 }
 
 // EndpointSpecificCheck is the interface which determines the state of the operation we are currently checking.
 type EndpointSpecificCheck interface {
 	// Returns the state of the operation and whether this check should be ignored
-	isCurrentOperationFinished(pc *PublishCheck) (operationFinished, ignoreCheck bool)
+	isCurrentOperationFinished(ctx context.Context, pc *PublishCheck) (operationFinished, ignoreCheck bool)
+}
+
+const (
+	// the operation was notified more than once
+	findingDuplicateNotification = "duplicate-notification"
+	// the notification of the operation arrived before or after the notification of a
+	// later or earlier operation, as told by their lastModified dates
+	findingOutOfOrderNotification = "out-of-order-notification"
+	// the notification is an UPDATE for content marked deleted, or the other way round
+	findingWrongNotificationType = "wrong-notification-type"
+)
+
+// qualityCheck is implemented by the checks which can report issues with an
+// operation, besides whether it finished.
+type qualityCheck interface {
+	// Returns the quality findings about the finished operation
+	findings(ctx context.Context, pc *PublishCheck) []string
+}
+
+// watchedQualityCheck is implemented by the quality checks whose findings can
+// still change once the operation finished, ex. when it is notified again.
+// Their findings are evaluated at the end of the grace window.
+type watchedQualityCheck interface {
+	qualityCheck
+	watchesFindings()
+}
+
+// inconclusiveCheck is implemented by the checks which may not have observed
+// the operation even though it finished.
+type inconclusiveCheck interface {
+	// Returns whether the operation could have been missed since it was published
+	isInconclusive(pc *PublishCheck) bool
 }
 
 // ContentCheck implements the EndpointSpecificCheck interface to check operation
@@ -43,19 +80,19 @@ type ContentNeo4jCheck struct {
 	httpCaller checks.HttpCaller
 }
 
-func (c ContentNeo4jCheck) isCurrentOperationFinished(pc *PublishCheck) (operationFinished, ignoreCheck bool) {
+func (c ContentNeo4jCheck) isCurrentOperationFinished(ctx context.Context, pc *PublishCheck) (operationFinished, ignoreCheck bool) {
 
 	pm := pc.Metric
 	url := pm.endpoint.String() + pm.UUID
 
-	resp, err := c.httpCaller.DoCall(checks.Config{
+	resp, err := c.httpCaller.DoCall(ctx, checks.Config{
 		Url:      url,
 		Username: pc.username,
 		Password: pc.password,
 		TxId:     checks.ConstructPamTxId(pm.tid)})
 
 	if err != nil {
-		log.Warnf("Error calling URL: [%v] for %s : [%v]", url, pc, err.Error())
+		pc.logger().Warnf("Error calling URL: [%v] : [%v]", url, err.Error())
 		return false, false
 	}
 
@@ -64,14 +101,14 @@ func (c ContentNeo4jCheck) isCurrentOperationFinished(pc *PublishCheck) (operati
 	// if the article was marked as deleted, operation is finished when the
 	// article cannot be found anymore
 	if pm.isMarkedDeleted {
-		log.Infof("Content Marked deleted. Checking %s, status code [%v]", pc, resp.StatusCode)
+		pc.logger().Infof("Content Marked deleted. Status code [%v]", resp.StatusCode)
 		return resp.StatusCode == 404, false
 	}
 
 	// if not marked deleted, operation isn't finished until status is 200
 	if resp.StatusCode != 200 {
 		if resp.StatusCode != 404 {
-			log.Infof("Checking %s, status code [%v]", pc, resp.StatusCode)
+			pc.logger().Infof("Status code [%v]", resp.StatusCode)
 		}
 		return false, false
 	}
@@ -80,12 +117,7 @@ func (c ContentNeo4jCheck) isCurrentOperationFinished(pc *PublishCheck) (operati
 	// this way we can handle updates
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Warnf("Checking %s. Cannot read response: [%s]",
-			loggingContextForCheck(pm.config.Alias,
-				pm.UUID,
-				pm.platform,
-				pm.tid),
-			err.Error())
+		pc.logger().Warnf("Cannot read response: [%s]", err.Error())
 		return false, false
 	}
 
@@ -93,8 +125,7 @@ func (c ContentNeo4jCheck) isCurrentOperationFinished(pc *PublishCheck) (operati
 
 	err = json.Unmarshal(data, &jsonResp)
 	if err != nil {
-		log.Warnf("Checking %s. Cannot unmarshal JSON response: [%s]",
-			loggingContextForCheck(pm.config.Alias, pm.UUID, pm.platform, pm.tid), err.Error())
+		pc.logger().Warnf("Cannot unmarshal JSON response: [%s]", err.Error())
 		return false, false
 	}
 
@@ -111,14 +142,15 @@ type S3Check struct {
 // to check the operation is present in the notification feed
 type NotificationsCheck struct {
 	httpCaller      checks.HttpCaller
-	subscribedFeeds map[string][]feeds.Feed
+	subscribedFeeds map[string][]feeds.Feed //read under subscribedFeedsLock
 	feedName        string
 }
 
 // NewPublishCheck returns a PublishCheck ready to perform a check for pm.UUID, at the
 // pm.endpoint.
 func NewPublishCheck(pm PublishMetric, username string, password string, t int, ci int, rs chan PublishMetric) *PublishCheck {
-	return &PublishCheck{pm, username, password, t, ci, rs}
+	pm.threshold = t
+	return &PublishCheck{Metric: pm, username: username, password: password, Threshold: t, CheckInterval: ci, ResultSink: rs}
 }
 
 var endpointSpecificChecks map[string]EndpointSpecificCheck
@@ -142,30 +174,66 @@ func init() {
 	}
 }
 
+// registerFeedChecks checks the endpoints configured with a feed type, and no
+// check of their own, in the notifications feed of their alias.
+func registerFeedChecks(metrics []MetricConfig) {
+	hC := checks.NewHttpCaller(10)
+	for _, metric := range metrics {
+		if _, found := endpointSpecificChecks[metric.Alias]; found || metric.FeedType == "" {
+			continue
+		}
+		endpointSpecificChecks[metric.Alias] = NotificationsCheck{hC, subscribedFeeds, metric.Alias}
+	}
+}
+
 // DoCheck performs an availability check on a piece of content at a certain
 // endpoint, applying endpoint-specific processing.
 // Returns true if the content is available at the endpoint, false otherwise.
-func (pc PublishCheck) DoCheck() (checkSuccessful, ignoreCheck bool) {
-	log.Infof("Running check for %s\n", pc)
+func (pc PublishCheck) DoCheck(ctx context.Context) (checkSuccessful, ignoreCheck bool) {
+	pc.logger().Info("Running check")
 	check := endpointSpecificChecks[pc.Metric.config.Alias]
 	if check == nil {
-		log.Warnf("No check for %s", pc)
+		pc.logger().Warn("No check for the endpoint")
 		return false, false
 	}
 
-	return check.isCurrentOperationFinished(&pc)
+	return check.isCurrentOperationFinished(ctx, &pc)
+}
+
+// IsInconclusive tells whether an unsuccessful check could not tell that the
+// operation is missing, ex. because the notifications feed fell behind.
+func (pc PublishCheck) IsInconclusive() bool {
+	check, ok := endpointSpecificChecks[pc.Metric.config.Alias].(inconclusiveCheck)
+	return ok && check.isInconclusive(&pc)
+}
+
+// Findings returns the quality issues found about the operation once it finished.
+func (pc PublishCheck) Findings(ctx context.Context) []string {
+	check, ok := endpointSpecificChecks[pc.Metric.config.Alias].(qualityCheck)
+	if !ok {
+		return nil
+	}
+	return check.findings(ctx, &pc)
+}
+
+// watchesFindings tells whether the findings of the check are evaluated at the
+// end of the grace window, rather than once the operation finished.
+func (pc PublishCheck) watchesFindings() bool {
+	_, ok := endpointSpecificChecks[pc.Metric.config.Alias].(watchedQualityCheck)
+	return ok
 }
 
-func (pc PublishCheck) String() string {
-	return loggingContextForCheck(pc.Metric.config.Alias, pc.Metric.UUID, pc.Metric.platform, pc.Metric.tid)
+// logger logs with the fields which identify the check.
+func (pc PublishCheck) logger() *log.Entry {
+	return loggerForCheck(pc.Metric.config.Alias, pc.Metric.UUID, pc.Metric.platform, pc.Metric.tid)
 }
 
-func (c ContentCheck) isCurrentOperationFinished(pc *PublishCheck) (operationFinished, ignoreCheck bool) {
+func (c ContentCheck) isCurrentOperationFinished(ctx context.Context, pc *PublishCheck) (operationFinished, ignoreCheck bool) {
 	pm := pc.Metric
 	url := pm.endpoint.String() + pm.UUID
-	resp, err := c.httpCaller.DoCall(checks.Config{Url: url, Username: pc.username, Password: pc.password, TxId: checks.ConstructPamTxId(pm.tid)})
+	resp, err := c.httpCaller.DoCall(ctx, checks.Config{Url: url, Username: pc.username, Password: pc.password, TxId: checks.ConstructPamTxId(pm.tid)})
 	if err != nil {
-		log.Warnf("Error calling URL: [%v] for %s : [%v]", url, pc, err.Error())
+		pc.logger().Warnf("Error calling URL: [%v] : [%v]", url, err.Error())
 		return false, false
 	}
 	defer cleanupResp(resp)
@@ -173,14 +241,14 @@ func (c ContentCheck) isCurrentOperationFinished(pc *PublishCheck) (operationFin
 	// if the article was marked as deleted, operation is finished when the
 	// article cannot be found anymore
 	if pm.isMarkedDeleted {
-		log.Infof("Content Marked deleted. Checking %s, status code [%v]", pc, resp.StatusCode)
+		pc.logger().Infof("Content Marked deleted. Status code [%v]", resp.StatusCode)
 		return resp.StatusCode == 404, false
 	}
 
 	// if not marked deleted, operation isn't finished until status is 200
 	if resp.StatusCode != 200 {
 		if resp.StatusCode != 404 {
-			log.Infof("Checking %s, status code [%v]", pc, resp.StatusCode)
+			pc.logger().Infof("Status code [%v]", resp.StatusCode)
 		}
 		return false, false
 	}
@@ -189,8 +257,7 @@ func (c ContentCheck) isCurrentOperationFinished(pc *PublishCheck) (operationFin
 	// this way we can handle updates
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Warnf("Checking %s. Cannot read response: [%s]",
-			loggingContextForCheck(pm.config.Alias, pm.UUID, pm.platform, pm.tid), err.Error())
+		pc.logger().Warnf("Cannot read response: [%s]", err.Error())
 		return false, false
 	}
 
@@ -198,8 +265,7 @@ func (c ContentCheck) isCurrentOperationFinished(pc *PublishCheck) (operationFin
 
 	err = json.Unmarshal(data, &jsonResp)
 	if err != nil {
-		log.Warnf("Checking %s. Cannot unmarshal JSON response: [%s]",
-			loggingContextForCheck(pm.config.Alias, pm.UUID, pm.platform, pm.tid), err.Error())
+		pc.logger().Warnf("Cannot unmarshal JSON response: [%s]", err.Error())
 		return false, false
 	}
 
@@ -209,7 +275,7 @@ func (c ContentCheck) isCurrentOperationFinished(pc *PublishCheck) (operationFin
 func isSamePublishEvent(jsonContent map[string]interface{}, pc *PublishCheck) (operationFinished, ignoreCheck bool) {
 	pm := pc.Metric
 	if jsonContent["publishReference"] == pm.tid {
-		log.Infof("Checking %s. Matched publish reference.", pc)
+		pc.logger().Info("Matched publish reference.")
 		return true, false
 	}
 
@@ -217,16 +283,16 @@ func isSamePublishEvent(jsonContent map[string]interface{}, pc *PublishCheck) (o
 	lastModifiedDate, ok := parseLastModifiedDate(jsonContent)
 	if ok {
 		if (*lastModifiedDate).After(pm.publishDate) {
-			log.Infof("Checking %s. Last modified date [%v] is after publish date [%v]", pc, lastModifiedDate, pm.publishDate)
+			pc.logger().Infof("Last modified date [%v] is after publish date [%v]", lastModifiedDate, pm.publishDate)
 			return false, true
 		}
 		if (*lastModifiedDate).Equal(pm.publishDate) {
-			log.Infof("Checking %s. Last modified date [%v] is equal to publish date [%v]", pc, lastModifiedDate, pm.publishDate)
+			pc.logger().Infof("Last modified date [%v] is equal to publish date [%v]", lastModifiedDate, pm.publishDate)
 			return true, false
 		}
-		log.Infof("Checking %s. Last modified date [%v] is before publish date [%v]", pc, lastModifiedDate, pm.publishDate)
+		pc.logger().Infof("Last modified date [%v] is before publish date [%v]", lastModifiedDate, pm.publishDate)
 	} else {
-		log.Warnf("The field 'lastModified' is not valid: [%v]. Skip checking rapid-fire publishes for %s.", jsonContent["lastModified"], pc)
+		pc.logger().Warnf("The field 'lastModified' is not valid: [%v]. Skip checking rapid-fire publishes.", jsonContent["lastModified"])
 	}
 
 	return false, false
@@ -242,12 +308,12 @@ func parseLastModifiedDate(jsonContent map[string]interface{}) (*time.Time, bool
 }
 
 // ignoreCheck is always false
-func (s S3Check) isCurrentOperationFinished(pc *PublishCheck) (operationFinished, ignoreCheck bool) {
+func (s S3Check) isCurrentOperationFinished(ctx context.Context, pc *PublishCheck) (operationFinished, ignoreCheck bool) {
 	pm := pc.Metric
 	url := pm.endpoint.String() + pm.UUID
-	resp, err := s.httpCaller.DoCall(checks.Config{Url: url})
+	resp, err := s.httpCaller.DoCall(ctx, checks.Config{Url: url})
 	if err != nil {
-		log.Warnf("Checking %s. Error calling URL: [%v] : [%v]", loggingContextForCheck(pm.config.Alias, pm.UUID, pm.platform, pm.tid), url, err.Error())
+		pc.logger().Warnf("Error calling URL: [%v] : [%v]", url, err.Error())
 		return false, false
 	}
 	defer cleanupResp(resp)
@@ -255,7 +321,7 @@ func (s S3Check) isCurrentOperationFinished(pc *PublishCheck) (operationFinished
 	if resp.StatusCode != 200 {
 		/*	for S3 files, we're getting a 403 if the files are not yet in, so we're not warning on that */
 		if resp.StatusCode != 403 {
-			log.Warnf("Checking %s. Error calling URL: [%v] : Response status: [%v]", loggingContextForCheck(pm.config.Alias, pm.UUID, pm.platform, pm.tid), url, resp.Status)
+			pc.logger().Warnf("Error calling URL: [%v] : Response status: [%v]", url, resp.Status)
 		}
 		return false, false
 	}
@@ -264,18 +330,18 @@ func (s S3Check) isCurrentOperationFinished(pc *PublishCheck) (operationFinished
 	// uploaded to S3, but body is empty - in this case, we get 200 back but empty body
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Warnf("Checking %s. Cannot read response: [%s]", loggingContextForCheck(pm.config.Alias, pm.UUID, pm.platform, pm.tid), err.Error())
+		pc.logger().Warnf("Cannot read response: [%s]", err.Error())
 		return false, false
 	}
 
 	if len(data) == 0 {
-		log.Warnf("Checking %s. Image body is empty!", loggingContextForCheck(pm.config.Alias, pm.UUID, pm.platform, pm.tid))
+		pc.logger().Warn("Image body is empty!")
 		return false, false
 	}
 	return true, false
 }
 
-func (n NotificationsCheck) isCurrentOperationFinished(pc *PublishCheck) (operationFinished, ignoreCheck bool) {
+func (n NotificationsCheck) isCurrentOperationFinished(ctx context.Context, pc *PublishCheck) (operationFinished, ignoreCheck bool) {
 	notifications := n.checkFeed(pc.Metric.UUID, pc.Metric.platform)
 	for _, e := range notifications {
 		checkData := map[string]interface{}{"publishReference": e.PublishReference, "lastModified": e.LastModified}
@@ -285,18 +351,18 @@ func (n NotificationsCheck) isCurrentOperationFinished(pc *PublishCheck) (operat
 		}
 	}
 
-	return false, n.shouldSkipCheck(pc)
+	return false, n.shouldSkipCheck(ctx, pc)
 }
 
-func (n NotificationsCheck) shouldSkipCheck(pc *PublishCheck) bool {
+func (n NotificationsCheck) shouldSkipCheck(ctx context.Context, pc *PublishCheck) bool {
 	pm := pc.Metric
 	if !pm.isMarkedDeleted {
 		return false
 	}
 	url := pm.endpoint.String() + "/" + pm.UUID
-	resp, err := n.httpCaller.DoCall(checks.Config{Url: url, Username: pc.username, Password: pc.password, TxId: checks.ConstructPamTxId(pm.tid)})
+	resp, err := n.httpCaller.DoCall(ctx, checks.Config{Url: url, Username: pc.username, Password: pc.password, TxId: checks.ConstructPamTxId(pm.tid)})
 	if err != nil {
-		log.Warnf("Checking %s. Error calling URL: [%v] : [%v]", loggingContextForCheck(pm.config.Alias, pm.UUID, pm.platform, pm.tid), url, err.Error())
+		pc.logger().Warnf("Error calling URL: [%v] : [%v]", url, err.Error())
 		return false
 	}
 	defer cleanupResp(resp)
@@ -318,18 +384,103 @@ func (n NotificationsCheck) shouldSkipCheck(pc *PublishCheck) bool {
 	return false
 }
 
+// watchesFindings makes the notifications delivered again or out of order
+// after the first one part of the findings.
+func (n NotificationsCheck) watchesFindings() {}
+
+func (n NotificationsCheck) findings(ctx context.Context, pc *PublishCheck) []string {
+	pm := pc.Metric
+	notifications := n.checkFeed(pm.UUID, pm.platform)
+
+	var findings []string
+	var matching []int
+	for i, e := range notifications {
+		if e.PublishReference == pm.tid {
+			matching = append(matching, i)
+		}
+	}
+	if len(matching) > 1 {
+		findings = append(findings, findingDuplicateNotification)
+	}
+
+	expectedType := feeds.UpdateNotification
+	if pm.isMarkedDeleted {
+		expectedType = feeds.DeleteNotification
+	}
+	for _, i := range matching {
+		if t := notifications[i].Type; t != "" && t != expectedType {
+			findings = append(findings, findingWrongNotificationType)
+			break
+		}
+	}
+
+	for _, i := range matching {
+		if isOutOfOrder(notifications, i) {
+			findings = append(findings, findingOutOfOrderNotification)
+			break
+		}
+	}
+
+	if len(findings) > 0 {
+		pc.logger().Warnf("Notifications %v: %v", findings, notifications)
+	}
+	return findings
+}
+
+// isOutOfOrder tells whether the notification at index i arrived after one
+// with a later lastModified date, or before one with an earlier date.
+func isOutOfOrder(notifications []*feeds.Notification, i int) bool {
+	lastModified, err := time.Parse(time.RFC3339Nano, notifications[i].LastModified)
+	if err != nil {
+		return false
+	}
+	for j, e := range notifications {
+		other, err := time.Parse(time.RFC3339Nano, e.LastModified)
+		if err != nil {
+			continue
+		}
+		if (j < i && other.After(lastModified)) || (j > i && other.Before(lastModified)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n NotificationsCheck) isInconclusive(pc *PublishCheck) bool {
+	pm := pc.Metric
+	gapReporter, ok := n.feed(pm.platform).(feeds.GapReporter)
+	if !ok {
+		return false
+	}
+	gaps := gapReporter.GapsSince(pm.publishDate)
+	if len(gaps) == 0 {
+		return false
+	}
+	pc.logger().Warnf("The notifications feed may have missed notifications from [%v] to [%v]", gaps[0].From.Format(time.RFC3339), gaps[len(gaps)-1].To.Format(time.RFC3339))
+	return true
+}
+
 func (n NotificationsCheck) checkFeed(uuid string, envName string) []*feeds.Notification {
+	if f := n.feed(envName); f != nil {
+		return f.NotificationsFor(uuid)
+	}
+
+	return []*feeds.Notification{}
+}
+
+func (n NotificationsCheck) feed(envName string) feeds.Feed {
+	subscribedFeedsLock.RLock()
 	envFeeds, found := n.subscribedFeeds[envName]
+	subscribedFeedsLock.RUnlock()
 	if found {
 		for _, f := range envFeeds {
 			if f.FeedName() == n.feedName {
-				notifications := f.NotificationsFor(uuid)
-				return notifications
+				return f
 			}
 		}
 	}
 
-	return []*feeds.Notification{}
+	return nil
 }
 
 func cleanupResp(resp *http.Response) {
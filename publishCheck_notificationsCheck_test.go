@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -41,6 +42,21 @@ func mockFeed(name string, uuid string, notifications []*feeds.Notification) tes
 	return testFeed{name, feeds.NotificationsPull, uuid, notifications}
 }
 
+type testGappyFeed struct {
+	testFeed
+	gaps []feeds.Gap
+}
+
+func (f testGappyFeed) GapsSince(t time.Time) []feeds.Gap {
+	var gaps []feeds.Gap
+	for _, g := range f.gaps {
+		if g.To.After(t) {
+			gaps = append(gaps, g)
+		}
+	}
+	return gaps
+}
+
 func TestFeedContainsMatchingNotification(t *testing.T) {
 	testUuid := uuid.NewV4().String()
 	testTxID := "tid_0123wxyz"
@@ -59,7 +75,7 @@ func TestFeedContainsMatchingNotification(t *testing.T) {
 	}
 
 	pc := NewPublishCheck(newPublishMetricBuilder().withUUID(testUuid).withPlatform(testEnv).withTID(testTxID).build(), "", "", 0, 0, nil)
-	finished, _ := notificationsCheck.isCurrentOperationFinished(pc)
+	finished, _ := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.True(t, finished, "Operation should be considered finished")
 }
 
@@ -78,7 +94,7 @@ func TestFeedMissingNotification(t *testing.T) {
 	}
 
 	pc := NewPublishCheck(newPublishMetricBuilder().withUUID(testUuid).withPlatform(testEnv).withTID(testTxID).build(), "", "", 0, 0, nil)
-	finished, _ := notificationsCheck.isCurrentOperationFinished(pc)
+	finished, _ := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.False(t, finished, "Operation should not be considered finished")
 }
 
@@ -102,7 +118,7 @@ func TestFeedContainsEarlierNotification(t *testing.T) {
 	}
 
 	pc := NewPublishCheck(newPublishMetricBuilder().withUUID(testUuid).withPlatform(testEnv).withTID(testTxID2).withPublishDate(testLastModified2).build(), "", "", 0, 0, nil)
-	finished, ignore := notificationsCheck.isCurrentOperationFinished(pc)
+	finished, ignore := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.False(t, finished, "Operation should not be considered finished")
 	assert.False(t, ignore, "Operation should not be skipped")
 }
@@ -127,7 +143,7 @@ func TestFeedContainsLaterNotification(t *testing.T) {
 	}
 
 	pc := NewPublishCheck(newPublishMetricBuilder().withUUID(testUuid).withPlatform(testEnv).withTID(testTxID2).withPublishDate(testLastModified2).build(), "", "", 0, 0, nil)
-	_, ignore := notificationsCheck.isCurrentOperationFinished(pc)
+	_, ignore := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.True(t, ignore, "Operation should be skipped")
 }
 
@@ -151,7 +167,7 @@ func TestFeedContainsUnparseableNotification(t *testing.T) {
 	}
 
 	pc := NewPublishCheck(newPublishMetricBuilder().withUUID(testUuid).withPlatform(testEnv).withTID(testTxID2).withPublishDate(testLastModified2).build(), "", "", 0, 0, nil)
-	finished, ignore := notificationsCheck.isCurrentOperationFinished(pc)
+	finished, ignore := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.False(t, finished, "Operation should not be considered finished")
 	assert.False(t, ignore, "Operation should not be skipped")
 }
@@ -174,7 +190,7 @@ func TestMissingFeed(t *testing.T) {
 	}
 
 	pc := NewPublishCheck(newPublishMetricBuilder().withUUID(testUuid).withPlatform(testEnv).withTID(testTxID).build(), "", "", 0, 0, nil)
-	finished, ignore := notificationsCheck.isCurrentOperationFinished(pc)
+	finished, ignore := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.False(t, finished, "Operation should not be considered finished")
 	assert.False(t, ignore, "Operation should not be ignored")
 }
@@ -197,7 +213,7 @@ func TestMissingEnvironment(t *testing.T) {
 	}
 
 	pc := NewPublishCheck(newPublishMetricBuilder().withUUID(testUuid).withPlatform(testEnv).withTID(testTxID).build(), "", "", 0, 0, nil)
-	finished, ignore := notificationsCheck.isCurrentOperationFinished(pc)
+	finished, ignore := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.False(t, finished, "Operation should not be considered finished")
 	assert.False(t, ignore, "Operation should not be ignored")
 }
@@ -207,7 +223,7 @@ func TestShouldSkipCheck_ContentIsNotMarkedAsDeleted_CheckNotSkipped(t *testing.
 	notificationsCheck := NotificationsCheck{}
 	pc := NewPublishCheck(pm, "", "", 0, 0, nil)
 
-	if notificationsCheck.shouldSkipCheck(pc) {
+	if notificationsCheck.shouldSkipCheck(context.Background(), pc) {
 		t.Errorf("Expected failure")
 	}
 }
@@ -218,7 +234,7 @@ func TestShouldSkipCheck_ContentIsMarkedAsDeletedPreviousNotificationsExist_Chec
 	notificationsCheck := NotificationsCheck{
 		mockHTTPCaller(t, "", buildResponse(200, `[{"id": "foobar", "lastModified" : "foobaz", "publishReference" : "unitTestRef" }]`)), nil, feedName,
 	}
-	if notificationsCheck.shouldSkipCheck(pc) {
+	if notificationsCheck.shouldSkipCheck(context.Background(), pc) {
 		t.Errorf("Expected failure")
 	}
 }
@@ -229,7 +245,75 @@ func TestShouldSkipCheck_ContentIsMarkedAsDeletedPreviousNotificationsDoesNotExi
 	notificationsCheck := NotificationsCheck{
 		mockHTTPCaller(t, "", buildResponse(200, `[]`)), nil, feedName,
 	}
-	if !notificationsCheck.shouldSkipCheck(pc) {
+	if !notificationsCheck.shouldSkipCheck(context.Background(), pc) {
 		t.Errorf("Expected success")
 	}
 }
+
+func TestFeedGapSincePublishIsInconclusive(t *testing.T) {
+	publishDate := time.Now().Add(-time.Minute)
+	f := testGappyFeed{
+		mockFeed(feedName, "", []*feeds.Notification{}),
+		[]feeds.Gap{{From: publishDate.Add(-time.Hour), To: publishDate.Add(time.Second)}},
+	}
+	notificationsCheck := NotificationsCheck{nil, map[string][]feeds.Feed{testEnv: {f}}, feedName}
+
+	pc := NewPublishCheck(newPublishMetricBuilder().withPlatform(testEnv).withPublishDate(publishDate).build(), "", "", 0, 0, nil)
+	assert.True(t, notificationsCheck.isInconclusive(pc), "the notification may have been missed in the gap")
+
+	pc = NewPublishCheck(newPublishMetricBuilder().withPlatform(testEnv).withPublishDate(publishDate.Add(2*time.Second)).build(), "", "", 0, 0, nil)
+	assert.False(t, notificationsCheck.isInconclusive(pc), "gaps before the publish are not relevant")
+}
+
+func TestFeedWithoutGapsIsNeverInconclusive(t *testing.T) {
+	f := mockFeed(feedName, "", []*feeds.Notification{})
+	notificationsCheck := NotificationsCheck{nil, map[string][]feeds.Feed{testEnv: {f}}, feedName}
+
+	pc := NewPublishCheck(newPublishMetricBuilder().withPlatform(testEnv).withPublishDate(time.Now()).build(), "", "", 0, 0, nil)
+	assert.False(t, notificationsCheck.isInconclusive(pc))
+}
+
+func TestNotificationsFindings(t *testing.T) {
+	update := feeds.UpdateNotification
+	del := feeds.DeleteNotification
+	var testCases = []struct {
+		description   string
+		markedDeleted bool
+		notifications []*feeds.Notification
+		expected      []string
+	}{
+		{"a single notification", false, []*feeds.Notification{
+			{Type: update, PublishReference: "tid_0", LastModified: "2016-10-28T14:00:00.000Z"},
+			{Type: update, PublishReference: "tid_1", LastModified: "2016-10-28T15:00:00.000Z"},
+		}, nil},
+		{"a notification without type", true, []*feeds.Notification{
+			{PublishReference: "tid_1", LastModified: "2016-10-28T15:00:00.000Z"},
+		}, nil},
+		{"a duplicate notification", false, []*feeds.Notification{
+			{Type: update, PublishReference: "tid_1", LastModified: "2016-10-28T15:00:00.000Z"},
+			{Type: update, PublishReference: "tid_1", LastModified: "2016-10-28T15:00:00.000Z"},
+		}, []string{findingDuplicateNotification}},
+		{"an update of deleted content", true, []*feeds.Notification{
+			{Type: update, PublishReference: "tid_1", LastModified: "2016-10-28T15:00:00.000Z"},
+		}, []string{findingWrongNotificationType}},
+		{"a delete of content which is not deleted", false, []*feeds.Notification{
+			{Type: del, PublishReference: "tid_1", LastModified: "2016-10-28T15:00:00.000Z"},
+		}, []string{findingWrongNotificationType}},
+		{"a notification after a later one", false, []*feeds.Notification{
+			{Type: update, PublishReference: "tid_2", LastModified: "2016-10-28T16:00:00.000Z"},
+			{Type: update, PublishReference: "tid_1", LastModified: "2016-10-28T15:00:00.000Z"},
+		}, []string{findingOutOfOrderNotification}},
+		{"a notification before an earlier one", false, []*feeds.Notification{
+			{Type: update, PublishReference: "tid_1", LastModified: "2016-10-28T15:00:00.000Z"},
+			{Type: update, PublishReference: "tid_0", LastModified: "2016-10-28T14:00:00+00:00"},
+		}, []string{findingOutOfOrderNotification}},
+	}
+
+	for _, tc := range testCases {
+		f := mockFeed(feedName, "", tc.notifications)
+		notificationsCheck := NotificationsCheck{nil, map[string][]feeds.Feed{testEnv: {f}}, feedName}
+		pc := NewPublishCheck(newPublishMetricBuilder().withPlatform(testEnv).withTID("tid_1").withMarkedDeleted(tc.markedDeleted).build(), "", "", 0, 0, nil)
+
+		assert.Equal(t, tc.expected, notificationsCheck.findings(context.Background(), pc), tc.description)
+	}
+}
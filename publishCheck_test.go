@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,7 +18,7 @@ func TestIsCurrentOperationFinished_S3Check_Finished(t *testing.T) {
 	s3Check := &S3Check{
 		mockHTTPCaller(t, "", buildResponse(200, "imagebytes")),
 	}
-	finished, _ := s3Check.isCurrentOperationFinished(NewPublishCheck(PublishMetric{}, "", "", 0, 0, nil))
+	finished, _ := s3Check.isCurrentOperationFinished(context.Background(), NewPublishCheck(PublishMetric{}, "", "", 0, 0, nil))
 	assert.True(t, finished, "operation should have finished successfully")
 }
 
@@ -29,7 +30,7 @@ func TestIsCurrentOperationFinished_S3Check_DoesNotSendAuthentication(t *testing
 
 	pm := newPublishMetricBuilder().withTID(currentTid).build()
 	pc := NewPublishCheck(pm, "jdoe", "frodo", 0, 0, nil)
-	finished, _ := s3Check.isCurrentOperationFinished(pc)
+	finished, _ := s3Check.isCurrentOperationFinished(context.Background(), pc)
 	assert.True(t, finished, "operation should have finished successfully")
 }
 
@@ -37,7 +38,7 @@ func TestIsCurrentOperationFinished_S3Check_Empty(t *testing.T) {
 	s3Check := &S3Check{
 		mockHTTPCaller(t, "", buildResponse(200, "")),
 	}
-	finished, _ := s3Check.isCurrentOperationFinished(NewPublishCheck(PublishMetric{}, "", "", 0, 0, nil))
+	finished, _ := s3Check.isCurrentOperationFinished(context.Background(), NewPublishCheck(PublishMetric{}, "", "", 0, 0, nil))
 	assert.False(t, finished, "operation should not have finished")
 }
 
@@ -45,7 +46,7 @@ func TestIsCurrentOperationFinished_S3Check_NotFinished(t *testing.T) {
 	s3Check := &S3Check{
 		mockHTTPCaller(t, "", buildResponse(404, "")),
 	}
-	finished, _ := s3Check.isCurrentOperationFinished(NewPublishCheck(PublishMetric{}, "", "", 0, 0, nil))
+	finished, _ := s3Check.isCurrentOperationFinished(context.Background(), NewPublishCheck(PublishMetric{}, "", "", 0, 0, nil))
 	assert.False(t, finished, "operation should not have finished")
 }
 
@@ -53,7 +54,7 @@ func TestIsCurrentOperationFinished_S3Check_NotFinished_On_403(t *testing.T) {
 	s3Check := &S3Check{
 		mockHTTPCaller(t, "", buildResponse(403, "")),
 	}
-	finished, _ := s3Check.isCurrentOperationFinished(NewPublishCheck(PublishMetric{}, "", "", 0, 0, nil))
+	finished, _ := s3Check.isCurrentOperationFinished(context.Background(), NewPublishCheck(PublishMetric{}, "", "", 0, 0, nil))
 	assert.False(t, finished, "operation should not have finished")
 }
 
@@ -65,7 +66,7 @@ func TestIsCurrentOperationFinished_ContentCheck_InvalidContent(t *testing.T) {
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, "", "", 0, 0, nil))
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
 	assert.False(t, finished, "Expected error.")
 }
 
@@ -77,7 +78,7 @@ func TestIsCurrentOperationFinished_ContentCheck_Finished(t *testing.T) {
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, "", "", 0, 0, nil))
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
 	assert.True(t, finished, "operation should have finished successfully")
 }
 
@@ -91,7 +92,7 @@ func TestIsCurrentOperationFinished_ContentCheck_WithAuthentication(t *testing.T
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, username, password, 0, 0, nil))
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, username, password, 0, 0, nil))
 	assert.True(t, finished, "operation should have finished successfully")
 }
 
@@ -103,7 +104,7 @@ func TestIsCurrentOperationFinished_ContentCheck_NotFinished(t *testing.T) {
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, "", "", 0, 0, nil))
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
 	assert.False(t, finished, "Expected failure.")
 }
 
@@ -115,7 +116,7 @@ func TestIsCurrentOperationFinished_ContentCheck_MarkedDeleted_Finished(t *testi
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withMarkedDeleted(true).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, "", "", 0, 0, nil))
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
 	assert.True(t, finished, "operation should have finished successfully.")
 }
 
@@ -127,7 +128,7 @@ func TestIsCurrentOperationFinished_ContentCheck_MarkedDeleted_NotFinished(t *te
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withMarkedDeleted(true).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, "", "", 0, 0, nil))
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
 	assert.False(t, finished, "operation should not have finished")
 }
 
@@ -142,7 +143,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsAfterCurrentP
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	_, ignoreCheck := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, "", "", 0, 0, nil))
+	_, ignoreCheck := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
 	assert.True(t, ignoreCheck, "check should be ignored")
 }
 
@@ -159,7 +160,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsBeforeCurrent
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	_, ignoreCheck := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, "", "", 0, 0, nil))
+	_, ignoreCheck := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
 	assert.False(t, ignoreCheck, "check should not be ignored")
 }
 
@@ -174,7 +175,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsBeforeCurrent
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	_, ignoreCheck := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, "", "", 0, 0, nil))
+	_, ignoreCheck := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
 	assert.False(t, ignoreCheck, "check should not be ignored")
 }
 
@@ -189,7 +190,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsBeforeCurrent
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, "", "", 0, 0, nil))
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
 	assert.False(t, finished, "operation should not have finished")
 }
 
@@ -205,7 +206,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateEqualsCurrentPu
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, "", "", 0, 0, nil))
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
 	assert.True(t, finished, "operation should have finished successfully")
 }
 
@@ -221,7 +222,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsNullCurrentTI
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, "", "", 0, 0, nil))
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
 	assert.True(t, finished, "operation should have finished successfully")
 }
 
@@ -237,7 +238,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsEmptyStringCu
 	}
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(pm, "", "", 0, 0, nil))
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(pm, "", "", 0, 0, nil))
 	assert.True(t, finished, "operation should have finished successfully")
 }
 
@@ -325,7 +326,7 @@ type testHTTPCaller struct {
 }
 
 // returns the mock responses of testHTTPCaller in order
-func (t *testHTTPCaller) DoCall(config checks.Config) (*http.Response, error) {
+func (t *testHTTPCaller) DoCall(ctx context.Context, config checks.Config) (*http.Response, error) {
 	if t.authUser != config.Username || t.authPass != config.Password {
 		return buildResponse(401, `{message: "Not authenticated"}`), nil
 	}
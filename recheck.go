@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/content"
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	apiKeyHeader = "X-Api-Key"
+	// how long to wait for the results after the threshold has passed
+	recheckGracePeriod = 30 * time.Second
+)
+
+// RecheckConfig holds the configuration of the recheck endpoint
+type RecheckConfig struct {
+	APIKey string `json:"apiKey"`
+}
+
+// recheckRequest is the JSON body of a recheck. Only the UUID is mandatory:
+// the missing transaction ID and content type are taken from the publish history.
+type recheckRequest struct {
+	UUID          string   `json:"uuid"`
+	ContentType   string   `json:"contentType"`
+	TransactionID string   `json:"transactionId"`
+	Environments  []string `json:"environments"`
+	Aliases       []string `json:"aliases"`
+}
+
+// recheckResult is the outcome of a recheck at one endpoint of one environment.
+type recheckResult struct {
+	UUID          string   `json:"uuid"`
+	TransactionID string   `json:"transactionId"`
+	Environment   string   `json:"environment"`
+	Alias         string   `json:"alias"`
+	Endpoint      string   `json:"endpoint"`
+	Outcome       string   `json:"outcome"`
+	UpperBound    int      `json:"upperBound,omitempty"`
+	Latency       float64  `json:"latency,omitempty"` //seconds, set when the content was found
+	Findings      []string `json:"findings,omitempty"`
+}
+
+// recheckContent is the content.Content rechecked on demand. It has no body,
+// so it is always considered valid.
+type recheckContent struct {
+	uuid        string
+	contentType string
+}
+
+func (c recheckContent) Initialize(binaryContent []byte) content.Content {
+	return c
+}
+
+func (c recheckContent) Validate(ctx context.Context, externalValidationEndpoint string, txID string, username string, password string) content.ValidationResponse {
+	return content.ValidationResponse{IsValid: true}
+}
+
+func (c recheckContent) GetType() string {
+	return c.contentType
+}
+
+func (c recheckContent) GetUUID() string {
+	return c.uuid
+}
+
+// recheckHandler schedules the checks of a piece of content on demand and
+// streams their results as JSON lines, as they complete.
+// The results are stored in the publish history, but are not sent to the
+// metric destinations, so that rechecks don't count against the publish SLA.
+func recheckHandler(apiKey string, history PublishHistory, envs *threadSafeEnvironments) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(apiKeyHeader)), []byte(apiKey)) != 1 {
+			writeJSONError(w, http.StatusUnauthorized, "invalid or missing API key")
+			return
+		}
+
+		var req recheckRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid recheck request: %v", err))
+			return
+		}
+
+		p, err := newRecheckParam(req, history, envs)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// the sink is large enough for all the results so that the checks never
+		// block, even if the client goes away
+		p.resultSink = make(chan PublishMetric, len(appConfig.MetricConf)*(p.environments.len()+1))
+		scheduled := scheduleChecks(context.Background(), p)
+		if scheduled == 0 {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("no checks configured for content type [%s]", req.ContentType))
+			return
+		}
+		log.Infof("Rechecking uuid=[%v] transaction_id=[%v] contentType=[%v] at [%d] endpoints", req.UUID, p.tid, req.ContentType, scheduled)
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		flusher, _ := w.(http.Flusher)
+		enc := json.NewEncoder(w)
+
+		deadline := time.After(time.Duration(appConfig.maxThresholdFor(req.ContentType)+appConfig.GraceWindow)*time.Second + recheckGracePeriod)
+		for received := 0; received < scheduled; received++ {
+			select {
+			case pm := <-p.resultSink:
+				enc.Encode(newRecheckResult(pm))
+				if flusher != nil {
+					flusher.Flush()
+				}
+			case <-deadline:
+				// checks are ignored, without a result, when the content was published again
+				log.Warnf("Recheck of uuid=[%v] transaction_id=[%v] got [%d] out of [%d] results", req.UUID, p.tid, received, scheduled)
+				return
+			}
+		}
+	}
+}
+
+func newRecheckParam(req recheckRequest, history PublishHistory, envs *threadSafeEnvironments) (*schedulerParam, error) {
+	if req.UUID == "" {
+		return nil, fmt.Errorf("invalid recheck request: uuid is mandatory")
+	}
+
+	p := &schedulerParam{
+		publishDate:     time.Now(),
+		tid:             req.TransactionID,
+		metricContainer: history,
+		environments:    envs,
+		aliases:         req.Aliases,
+	}
+
+	if req.TransactionID == "" || req.ContentType == "" {
+		records, err := history.Query(HistoryQuery{UUID: req.UUID, TransactionID: req.TransactionID, Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > 0 {
+			if p.tid == "" {
+				p.tid = records[0].TransactionID
+			}
+			if req.ContentType == "" {
+				req.ContentType = records[0].ContentType
+			}
+			p.isMarkedDeleted = records[0].IsMarkedDeleted
+		}
+	}
+	if p.tid == "" || req.ContentType == "" {
+		return nil, fmt.Errorf("invalid recheck request: transactionId and contentType are mandatory for uuid [%s] when they are not in the publish history", req.UUID)
+	}
+	p.contentToCheck = recheckContent{req.UUID, req.ContentType}
+
+	if len(req.Environments) > 0 {
+		p.environments = newThreadSafeEnvironments()
+		for _, name := range req.Environments {
+			env, found := envs.lookup(name)
+			if !found {
+				return nil, fmt.Errorf("invalid recheck request: unknown environment [%s]", name)
+			}
+			p.environments.envMap[name] = env
+		}
+	}
+
+	return p, nil
+}
+
+func newRecheckResult(pm PublishMetric) recheckResult {
+	result := recheckResult{
+		UUID:          pm.UUID,
+		TransactionID: pm.tid,
+		Environment:   pm.platform,
+		Alias:         pm.config.Alias,
+		Endpoint:      pm.endpoint.String(),
+		Outcome:       outcomeOf(pm),
+		Findings:      pm.findings,
+	}
+	if pm.latency > 0 {
+		result.UpperBound = pm.publishInterval.upperBound
+		result.Latency = pm.latency.Seconds()
+	}
+	return result
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedResultCheck struct {
+	finished bool
+}
+
+func (c fixedResultCheck) isCurrentOperationFinished(ctx context.Context, pc *PublishCheck) (operationFinished, ignoreCheck bool) {
+	return c.finished, false
+}
+
+func TestRecheckUsesThePublishHistory(t *testing.T) {
+	handler, history := newRecheckTestHandler()
+	pm := newHistoryTestMetric("uuid1", "tid_1", "env1", "content", time.Now().Add(-time.Hour), false)
+	pm.contentType = "EOM::CompoundStory"
+	history.Add(pm)
+
+	results := postRecheck(t, handler, "secret", `{"uuid": "uuid1"}`)
+
+	require.Len(t, results, 4)
+	byEnvAndAlias := make(map[string]recheckResult)
+	for _, r := range results {
+		assert.Equal(t, "uuid1", r.UUID)
+		assert.Equal(t, "tid_1", r.TransactionID)
+		byEnvAndAlias[r.Environment+"/"+r.Alias] = r
+	}
+	assert.Equal(t, outcomeOnTime, byEnvAndAlias["env1/content"].Outcome)
+	assert.Equal(t, outcomeOnTime, byEnvAndAlias["env2/content"].Outcome)
+	assert.Equal(t, outcomeMissing, byEnvAndAlias["env1/lists"].Outcome)
+	assert.Equal(t, outcomeMissing, byEnvAndAlias["env2/lists"].Outcome)
+
+	records, _ := history.Query(HistoryQuery{UUID: "uuid1", Outcome: outcomeOnTime})
+	assert.Len(t, records, 2, "recheck results should be stored in the history")
+}
+
+func TestRecheckFiltersEnvironmentsAndAliases(t *testing.T) {
+	handler, _ := newRecheckTestHandler()
+
+	results := postRecheck(t, handler, "secret", `{"uuid": "uuid1", "transactionId": "tid_1", "contentType": "EOM::CompoundStory", "environments": ["env2"], "aliases": ["content"]}`)
+
+	require.Len(t, results, 1)
+	assert.Equal(t, "env2", results[0].Environment)
+	assert.Equal(t, "content", results[0].Alias)
+	assert.Equal(t, outcomeOnTime, results[0].Outcome)
+}
+
+func TestRecheckRequiresTheAPIKey(t *testing.T) {
+	handler, _ := newRecheckTestHandler()
+
+	for _, key := range []string{"", "wrong"} {
+		req := httptest.NewRequest("POST", "/__recheck", strings.NewReader(`{"uuid": "uuid1"}`))
+		req.Header.Set(apiKeyHeader, key)
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestRecheckRejectsInvalidRequests(t *testing.T) {
+	handler, _ := newRecheckTestHandler()
+
+	for _, body := range []string{
+		`not json`,
+		`{}`,
+		`{"uuid": "unknown"}`,
+		`{"uuid": "uuid1", "transactionId": "tid_1", "contentType": "EOM::CompoundStory", "environments": ["env3"]}`,
+		`{"uuid": "uuid1", "transactionId": "tid_1", "contentType": "Image"}`,
+	} {
+		req := httptest.NewRequest("POST", "/__recheck", strings.NewReader(body))
+		req.Header.Set(apiKeyHeader, "secret")
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func newRecheckTestHandler() (func(w http.ResponseWriter, r *http.Request), PublishHistory) {
+	appConfig = &AppConfig{
+		Threshold: 2,
+		MetricConf: []MetricConfig{
+			{Endpoint: "/content/", Granularity: 2, Alias: "content", ContentTypes: []string{"EOM::CompoundStory"}},
+			{Endpoint: "/lists/", Granularity: 2, Alias: "lists", ContentTypes: []string{"EOM::CompoundStory"}},
+		},
+	}
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{
+		"content": fixedResultCheck{true},
+		"lists":   fixedResultCheck{false},
+	}
+
+	envs := newThreadSafeEnvironments()
+	envs.envMap["env1"] = Environment{"env1", "http://env1.example.org", "", "", ""}
+	envs.envMap["env2"] = Environment{"env2", "http://env2.example.org", "", "", ""}
+	history := newMemoryHistory(24 * time.Hour)
+	return recheckHandler("secret", history, envs), history
+}
+
+func postRecheck(t *testing.T, handler func(w http.ResponseWriter, r *http.Request), apiKey string, body string) []recheckResult {
+	req := httptest.NewRequest("POST", "/__recheck", strings.NewReader(body))
+	req.Header.Set(apiKeyHeader, apiKey)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	var results []recheckResult
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var r recheckResult
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		results = append(results, r)
+	}
+	return results
+}
@@ -1,13 +1,16 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"net/url"
 	"regexp"
 	"time"
 
 	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/Financial-Times/publish-availability-monitor/tracing"
 	log "github.com/Sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -16,16 +19,39 @@ var (
 
 type schedulerParam struct {
 	contentToCheck  content.Content
+	contentType     string //the type the content is monitored as, defaults to the type of contentToCheck
 	publishDate     time.Time
 	tid             string
 	isMarkedDeleted bool
-	metricContainer *publishHistory
+	metricContainer PublishHistory
 	environments    *threadSafeEnvironments
+	aliases         []string           //if set, only the endpoints with these aliases are checked
+	allEndpoints    bool               //if set, the endpoints are checked whatever the content types they monitor
+	resultSink      chan PublishMetric //defaults to metricSink
 }
 
-func scheduleChecks(p *schedulerParam) {
+// monitoringType returns the type the endpoints to check are selected by.
+func (p *schedulerParam) monitoringType() string {
+	if p.contentType != "" {
+		return p.contentType
+	}
+	return p.contentToCheck.GetType()
+}
+
+// scheduleChecks starts the checks for p.contentToCheck, which belong to the
+// span of ctx, and returns how many results will be sent to the result sink.
+func scheduleChecks(ctx context.Context, p *schedulerParam) int {
+	resultSink := p.resultSink
+	if resultSink == nil {
+		resultSink = metricSink
+	}
+
+	scheduled := 0
 	for _, metric := range appConfig.MetricConf {
-		if !validType(metric.ContentTypes, p.contentToCheck.GetType()) {
+		if !p.allEndpoints && !validType(metric.ContentTypes, p.monitoringType()) {
+			continue
+		}
+		if len(p.aliases) > 0 && !validType(p.aliases, metric.Alias) {
 			continue
 		}
 
@@ -51,101 +77,137 @@ func scheduleChecks(p *schedulerParam) {
 				}
 
 				var publishMetric = PublishMetric{
-					p.contentToCheck.GetUUID(),
-					false,
-					p.publishDate,
-					name,
-					Interval{},
-					metric,
-					*endpointURL,
-					p.tid,
-					p.isMarkedDeleted,
+					UUID:            p.contentToCheck.GetUUID(),
+					publishDate:     p.publishDate,
+					platform:        name,
+					config:          metric,
+					endpoint:        *endpointURL,
+					tid:             p.tid,
+					isMarkedDeleted: p.isMarkedDeleted,
+					contentType:     p.monitoringType(),
+					expectedFields:  expectedFieldsFor(metric, p.contentToCheck),
 				}
 
-				var checkInterval = appConfig.Threshold / metric.Granularity
-				var publishCheck = NewPublishCheck(publishMetric, env.Username, env.Password, appConfig.Threshold, checkInterval, metricSink)
+				var threshold = appConfig.thresholdFor(metric, p.monitoringType())
+				var checkInterval = checkIntervalFor(metric, threshold)
+				var publishCheck = NewPublishCheck(publishMetric, env.Username, env.Password, threshold, checkInterval, resultSink)
+				publishCheck.GraceWindow = appConfig.GraceWindow
+				publishCheck.spanContext = trace.SpanContextFromContext(ctx)
+				if p.resultSink == nil {
+					// rechecks are not resumed after a restart, as nobody waits for their results anymore
+					saveCheckpoint(publishCheck)
+				}
+				runningChecks.add()
 				go scheduleCheck(*publishCheck, p.metricContainer)
+				scheduled++
 			}
 		} else {
 			// generate a generic failure metric so that the absence of monitoring is logged
 			var publishMetric = PublishMetric{
-				p.contentToCheck.GetUUID(),
-				false,
-				p.publishDate,
-				"none",
-				Interval{},
-				metric,
-				url.URL{},
-				p.tid,
-				p.isMarkedDeleted,
+				UUID:            p.contentToCheck.GetUUID(),
+				publishDate:     p.publishDate,
+				platform:        "none",
+				config:          metric,
+				tid:             p.tid,
+				isMarkedDeleted: p.isMarkedDeleted,
+				contentType:     p.monitoringType(),
 			}
-			metricSink <- publishMetric
+			resultSink <- publishMetric
 			updateHistory(p.metricContainer, publishMetric)
+			scheduled++
 		}
 	}
+	return scheduled
 }
 
-func scheduleCheck(check PublishCheck, metricContainer *publishHistory) {
+// expectedFieldsFor returns the fields of c which are verified at the endpoint
+// of metric, or nil when none is.
+func expectedFieldsFor(metric MetricConfig, c content.Content) map[string]string {
+	verifiable, ok := c.(content.VerifiableContent)
+	if !ok || len(metric.VerifyFields) == 0 {
+		return nil
+	}
+
+	fields := verifiable.ExpectedFields()
+	expected := make(map[string]string)
+	for _, field := range metric.VerifyFields {
+		if value, found := fields[field]; found {
+			expected[field] = value
+		}
+	}
+	return expected
+}
+
+// scheduleCheck runs check until it succeeds or its SLA expires, and sends the
+// result to the check's sink. The check must be added to runningChecks beforehand.
+func scheduleCheck(check PublishCheck, metricContainer PublishHistory) {
+	defer runningChecks.done()
+	inFlight := checksInFlight.WithLabelValues(check.Metric.platform, check.Metric.config.Alias)
+	inFlight.Inc()
+	defer inFlight.Dec()
 
 	//the date the SLA expires for this publish event
 	publishSLA := check.Metric.publishDate.Add(time.Duration(check.Threshold) * time.Second)
+	//content found between the SLA and the end of the grace window is late
+	checksEnd := publishSLA.Add(time.Duration(check.GraceWindow) * time.Second)
 
 	//compute the actual seconds left until the SLA to compensate for the
 	//time passed between publish and the message reaching this point
 	secondsUntilSLA := publishSLA.Sub(time.Now()).Seconds()
-	log.Infof("Checking %s. [%v] seconds until SLA.",
-		loggingContextForCheck(check.Metric.config.Alias,
-			check.Metric.UUID,
-			check.Metric.platform,
-			check.Metric.tid),
-		int(secondsUntilSLA))
-
-	//used to signal the ticker to stop after the threshold duration is reached
+	check.logger().Infof("[%v] seconds until SLA.", int(secondsUntilSLA))
+
+	//used to signal the ticker to stop after the threshold duration and the grace window are over
+	secondsUntilEnd := checksEnd.Sub(time.Now()).Seconds()
 	quitChan := make(chan bool)
 	go func() {
-		<-time.After(time.Duration(secondsUntilSLA) * time.Second)
+		<-time.After(time.Duration(secondsUntilEnd) * time.Second)
 		close(quitChan)
 	}()
 
 	secondsSincePublish := time.Since(check.Metric.publishDate).Seconds()
-	log.Infof("Checking %s. [%v] seconds elapsed since publish.",
-		loggingContextForCheck(check.Metric.config.Alias,
-			check.Metric.UUID,
-			check.Metric.platform,
-			check.Metric.tid),
-		int(secondsSincePublish))
+	check.logger().Infof("[%v] seconds elapsed since publish.", int(secondsSincePublish))
 
 	elapsedIntervals := secondsSincePublish / float64(check.CheckInterval)
-	log.Infof("Checking %s. Skipping first [%v] checks",
-		loggingContextForCheck(check.Metric.config.Alias,
-			check.Metric.UUID,
-			check.Metric.platform,
-			check.Metric.tid),
-		int(elapsedIntervals))
+	check.logger().Infof("Skipping first [%v] checks", int(elapsedIntervals))
 
 	checkNr := int(elapsedIntervals) + 1
+	ctx := trace.ContextWithSpanContext(runningChecks.context(), check.spanContext)
 	// ticker to fire once per interval
 	tickerChan := time.NewTicker(time.Duration(check.CheckInterval) * time.Second)
 	for {
-		checkSuccessful, ignoreCheck := check.DoCheck()
+		checkSuccessful, ignoreCheck := check.tracedCheck(ctx, checkNr)
 		if ignoreCheck {
-			log.Infof("Ignore check for %s",
-				loggingContextForCheck(check.Metric.config.Alias,
-					check.Metric.UUID,
-					check.Metric.platform,
-					check.Metric.tid))
+			check.logger().Info("Ignore check")
 			tickerChan.Stop()
+			removeCheckpoint(check)
 			return
 		}
 		if checkSuccessful {
 			tickerChan.Stop()
-			check.Metric.publishOK = true
+			check.Metric.latency = time.Since(check.Metric.publishDate)
+			if time.Now().After(publishSLA) {
+				check.logger().Infof("Content arrived [%v] after the SLA", time.Since(publishSLA))
+				check.Metric.outcome = outcomeLate
+			} else {
+				check.Metric.publishOK = true
+			}
 
 			lower := (checkNr - 1) * check.CheckInterval
 			upper := checkNr * check.CheckInterval
 			check.Metric.publishInterval = Interval{lower, upper}
 
-			check.ResultSink <- check.Metric
+			if !check.watchesFindings() {
+				check.Metric.findings = check.Findings(ctx)
+				runningChecks.send(check.ResultSink, check.Metric)
+				updateHistory(metricContainer, check.Metric)
+				removeCheckpoint(check)
+				return
+			}
+
+			// the result is not held until the findings are known
+			runningChecks.send(check.ResultSink, check.Metric)
+			removeCheckpoint(check)
+			watchFindings(ctx, &check, checksEnd, quitChan)
 			updateHistory(metricContainer, check.Metric)
 			return
 		}
@@ -157,21 +219,51 @@ func scheduleCheck(check PublishCheck, metricContainer *publishHistory) {
 			tickerChan.Stop()
 			//if we get here, checks were unsuccessful
 			check.Metric.publishOK = false
-			check.ResultSink <- check.Metric
+			if check.IsInconclusive() {
+				check.Metric.outcome = outcomeInconclusive
+			}
+			runningChecks.send(check.ResultSink, check.Metric)
+			updateHistory(metricContainer, check.Metric)
+			removeCheckpoint(check)
+			return
+		case <-runningChecks.aborted():
+			tickerChan.Stop()
+			if check.checkpointID != 0 && checkpoints.Durable() {
+				// the check is resumed when the monitor restarts, and its result is recorded then
+				check.logger().Info("Aborting check until the monitor restarts")
+				return
+			}
+			check.logger().Info("Aborting check")
+			check.Metric.publishOK = false
+			check.Metric.outcome = outcomeAborted
+			runningChecks.send(check.ResultSink, check.Metric)
 			updateHistory(metricContainer, check.Metric)
+			removeCheckpoint(check)
 			return
 		}
 	}
 
 }
 
-func updateHistory(metricContainer *publishHistory, newPublishResult PublishMetric) {
-	metricContainer.Lock()
-	if len(metricContainer.publishMetrics) == 10 {
-		metricContainer.publishMetrics = metricContainer.publishMetrics[1:len(metricContainer.publishMetrics)]
+// watchFindings evaluates the findings of check, whose result was already sent,
+// at the end of the grace window, and reports them to the metric destinations
+// as a separate PublishMetric.
+func watchFindings(ctx context.Context, check *PublishCheck, checksEnd time.Time, quitChan <-chan bool) {
+	check.logger().Infof("Watching for findings until the end of the grace window, in [%v]", time.Until(checksEnd))
+	select {
+	case <-quitChan:
+	case <-runningChecks.aborted():
+		check.logger().Info("Reporting the findings before the end of the grace window, as the check is aborted")
+	}
+
+	check.Metric.findings = check.Findings(ctx)
+	if len(check.Metric.findings) == 0 || check.ResultSink != metricSink {
+		// rechecks and synthetic publishes only wait for the availability results
+		return
 	}
-	metricContainer.publishMetrics = append(metricContainer.publishMetrics, newPublishResult)
-	metricContainer.Unlock()
+	report := check.Metric
+	report.findingsReport = true
+	runningChecks.send(metricSink, report)
 }
 
 func validType(validTypes []string, eomType string) bool {
@@ -183,6 +275,28 @@ func validType(validTypes []string, eomType string) bool {
 	return false
 }
 
-func loggingContextForCheck(checkType string, uuid string, environment string, transactionID string) string {
-	return fmt.Sprintf("environment=[%v], checkType=[%v], uuid=[%v], transaction_id=[%v]", environment, checkType, uuid, transactionID)
+func loggerForCheck(checkType string, uuid string, environment string, transactionID string) *log.Entry {
+	return log.WithFields(log.Fields{
+		"environment":    environment,
+		"checkType":      checkType,
+		"uuid":           uuid,
+		"transaction_id": transactionID,
+	})
+}
+
+// tracedCheck runs the attempt checkNr of check, in a span of ctx.
+func (pc PublishCheck) tracedCheck(ctx context.Context, checkNr int) (checkSuccessful, ignoreCheck bool) {
+	ctx, span := tracing.Start(ctx, "scheduleCheck")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("transaction_id", pc.Metric.tid),
+		attribute.String("checkType", pc.Metric.config.Alias),
+		attribute.String("environment", pc.Metric.platform),
+		attribute.String("uuid", pc.Metric.UUID),
+		attribute.Int("attempt", checkNr),
+	)
+
+	checkSuccessful, ignoreCheck = pc.DoCheck(ctx)
+	span.SetAttributes(attribute.Bool("successful", checkSuccessful), attribute.Bool("ignored", ignoreCheck))
+	return checkSuccessful, ignoreCheck
 }
@@ -1,12 +1,18 @@
 package main
 
 import (
-	"sync"
+	"context"
 	"testing"
 	"time"
 
 	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/Financial-Times/publish-availability-monitor/feeds"
+	"github.com/Financial-Times/publish-availability-monitor/tracing"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestValidType(testing *testing.T) {
@@ -73,11 +79,10 @@ func TestScheduleChecksForS3AreCorrect(testing *testing.T) {
 	mockEnvironments.envMap["env1"] = Environment{"env1", readURL, s3URL, "user1", "pass1"}
 
 	capturingMetrics := runScheduleChecks(testing, validImageEomFile, mockEnvironments)
-	defer capturingMetrics.RUnlock()
 
 	require.NotNil(testing, capturingMetrics)
-	require.Equal(testing, 1, len(capturingMetrics.publishMetrics))
-	require.Equal(testing, s3URL+"/whatever/", capturingMetrics.publishMetrics[0].endpoint.String())
+	require.Equal(testing, 1, len(capturingMetrics))
+	require.Equal(testing, s3URL+"/whatever/", capturingMetrics[0].endpoint.String())
 }
 
 func TestScheduleChecksForContentAreCorrect(testing *testing.T) {
@@ -102,11 +107,10 @@ func TestScheduleChecksForContentAreCorrect(testing *testing.T) {
 	mockEnvironments.envMap["env1"] = Environment{"env1", readURL, s3URL, "user1", "pass1"}
 
 	capturingMetrics := runScheduleChecks(testing, validImageEomFile, mockEnvironments)
-	defer capturingMetrics.RUnlock()
 
 	require.NotNil(testing, capturingMetrics)
-	require.Equal(testing, 1, len(capturingMetrics.publishMetrics))
-	require.Equal(testing, readURL+"/whatever/", capturingMetrics.publishMetrics[0].endpoint.String())
+	require.Equal(testing, 1, len(capturingMetrics))
+	require.Equal(testing, readURL+"/whatever/", capturingMetrics[0].endpoint.String())
 }
 
 func TestScheduleChecksForContentWithInternalComponentsAreCorrect(testing *testing.T) {
@@ -133,11 +137,10 @@ func TestScheduleChecksForContentWithInternalComponentsAreCorrect(testing *testi
 	mockArticleEomFile.Type = "InternalComponents"
 
 	capturingMetrics := runScheduleChecks(testing, mockArticleEomFile, mockEnvironments)
-	defer capturingMetrics.RUnlock()
 
 	require.NotNil(testing, capturingMetrics)
-	require.Equal(testing, 1, len(capturingMetrics.publishMetrics))
-	require.Equal(testing, readURL+"/internalcomponents/", capturingMetrics.publishMetrics[0].endpoint.String())
+	require.Equal(testing, 1, len(capturingMetrics))
+	require.Equal(testing, readURL+"/internalcomponents/", capturingMetrics[0].endpoint.String())
 }
 
 func TestScheduleChecksForDynamicContentWithInternalComponentsAreCorrect(testing *testing.T) {
@@ -165,15 +168,208 @@ func TestScheduleChecksForDynamicContentWithInternalComponentsAreCorrect(testing
 	mockArticleEomFile.Type = "EOM::CompoundStory_DynamicContent"
 
 	capturingMetrics := runScheduleChecks(testing, mockArticleEomFile, mockEnvironments)
-	defer capturingMetrics.RUnlock()
 
 	require.NotNil(testing, capturingMetrics)
-	require.Equal(testing, 1, len(capturingMetrics.publishMetrics))
-	require.Equal(testing, readURL+"/internalcomponents/", capturingMetrics.publishMetrics[0].endpoint.String())
+	require.Equal(testing, 1, len(capturingMetrics))
+	require.Equal(testing, readURL+"/internalcomponents/", capturingMetrics[0].endpoint.String())
 }
 
-func runScheduleChecks(testing *testing.T, content content.Content, mockEnvironments *threadSafeEnvironments) *publishHistory {
-	capturingMetrics := &publishHistory{sync.RWMutex{}, make([]PublishMetric, 0)}
+func TestScheduleChecksUseTheEndpointThreshold(testing *testing.T) {
+	appConfig = &AppConfig{
+		MetricConf: []MetricConfig{
+			{
+				Endpoint:    "/whatever/",
+				Granularity: 1,
+				Threshold:   30,
+				Alias:       "content",
+				ContentTypes: []string{
+					"Image",
+				},
+			},
+		},
+		ContentTypeThresholds: map[string]int{"Image": 20},
+		Threshold:             1,
+	}
+
+	var mockEnvironments = newThreadSafeEnvironments()
+	mockEnvironments.envMap["env1"] = Environment{"env1", "http://env1.example.org", "http://s1.example.org", "user1", "pass1"}
+
+	capturingMetrics := runScheduleChecks(testing, validImageEomFile, mockEnvironments)
+
+	require.Equal(testing, 1, len(capturingMetrics))
+	require.Equal(testing, 30, capturingMetrics[0].threshold)
+}
+
+func TestScheduleCheckReportsLatePublishesWithinTheGraceWindow(testing *testing.T) {
+	runningChecks = newCheckTracker()
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": fixedResultCheck{true}}
+	results := make(chan PublishMetric, 1)
+
+	check := newShutdownTestCheck(results)
+	check.Metric.publishDate = time.Now().Add(-2 * time.Second)
+	check.Threshold = 1
+	check.GraceWindow = 60
+	runningChecks.add()
+	scheduleCheck(check, newMemoryHistory(time.Hour))
+
+	require.Len(testing, results, 1)
+	pm := <-results
+	require.False(testing, pm.publishOK)
+	require.Equal(testing, outcomeLate, outcomeOf(pm))
+	require.True(testing, pm.latency >= 2*time.Second, "the latency should be measured from the publish")
+}
+
+func TestScheduleCheckReportsMissingPublishesAfterTheGraceWindow(testing *testing.T) {
+	runningChecks = newCheckTracker()
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": fixedResultCheck{false}}
+	results := make(chan PublishMetric, 1)
+
+	check := newShutdownTestCheck(results)
+	check.Threshold = 0
+	check.GraceWindow = 1
+	runningChecks.add()
+	scheduleCheck(check, newMemoryHistory(time.Hour))
+
+	require.Len(testing, results, 1)
+	pm := <-results
+	require.Equal(testing, outcomeMissing, outcomeOf(pm))
+	require.Equal(testing, time.Duration(0), pm.latency)
+}
+
+func TestScheduleCheckReportsInconclusivePublishesWhenTheFeedHasAGap(testing *testing.T) {
+	runningChecks = newCheckTracker()
+	publishDate := time.Now()
+	f := testGappyFeed{
+		mockFeed("notifications", "", []*feeds.Notification{}),
+		[]feeds.Gap{{From: publishDate.Add(-time.Hour), To: publishDate.Add(time.Second)}},
+	}
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{
+		"content": NotificationsCheck{nil, map[string][]feeds.Feed{"env1": {f}}, "notifications"},
+	}
+	results := make(chan PublishMetric, 1)
+
+	check := newShutdownTestCheck(results)
+	check.Metric.publishDate = publishDate
+	check.Threshold = 0
+	check.GraceWindow = 1
+	runningChecks.add()
+	scheduleCheck(check, newMemoryHistory(time.Hour))
+
+	require.Len(testing, results, 1)
+	pm := <-results
+	require.False(testing, pm.publishOK)
+	require.Equal(testing, outcomeInconclusive, outcomeOf(pm))
+	require.False(testing, breachesSLA(outcomeOf(pm)))
+}
+
+func TestScheduleCheckReportsTheFindingsOfAvailableContent(testing *testing.T) {
+	defer func(saved chan PublishMetric) { metricSink = saved }(metricSink)
+	runningChecks = newCheckTracker()
+	n := feeds.Notification{Type: feeds.UpdateNotification, PublishReference: "tid_1", LastModified: time.Now().Format(time.RFC3339Nano)}
+	f := mockFeed("notifications", "", []*feeds.Notification{&n, &n})
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{
+		"content": NotificationsCheck{nil, map[string][]feeds.Feed{"env1": {f}}, "notifications"},
+	}
+	metricSink = make(chan PublishMetric, 2)
+	history := newMemoryHistory(time.Hour)
+
+	check := newShutdownTestCheck(metricSink)
+	check.Metric.publishDate = time.Now().Add(-time.Second)
+	check.Threshold = 2
+	runningChecks.add()
+	scheduleCheck(check, history)
+
+	require.Len(testing, metricSink, 2)
+	pm := <-metricSink
+	require.Equal(testing, outcomeOnTime, outcomeOf(pm))
+	require.False(testing, pm.findingsReport)
+	require.Empty(testing, pm.findings)
+	report := <-metricSink
+	require.True(testing, report.findingsReport)
+	require.Equal(testing, []string{findingDuplicateNotification}, report.findings)
+
+	records, err := history.Query(HistoryQuery{})
+	require.NoError(testing, err)
+	require.Len(testing, records, 1)
+	require.Equal(testing, []string{findingDuplicateNotification}, records[0].Findings)
+}
+
+func TestScheduleCheckReportsTheFindingsOfTheNotificationsReceivedWithinTheGraceWindow(testing *testing.T) {
+	defer func(saved chan PublishMetric) { metricSink = saved }(metricSink)
+	runningChecks = newCheckTracker()
+	n := feeds.Notification{Type: feeds.UpdateNotification, PublishReference: "tid_1", LastModified: time.Now().Format(time.RFC3339Nano)}
+	subscribed := map[string][]feeds.Feed{"env1": {mockFeed("notifications", "", []*feeds.Notification{&n})}}
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{
+		"content": NotificationsCheck{nil, subscribed, "notifications"},
+	}
+	metricSink = make(chan PublishMetric, 2)
+
+	check := newShutdownTestCheck(metricSink)
+	check.Metric.publishDate = time.Now().Add(-time.Second)
+	check.Threshold = 2
+	check.GraceWindow = 1
+	runningChecks.add()
+	go scheduleCheck(check, newMemoryHistory(time.Hour))
+
+	time.Sleep(500 * time.Millisecond)
+	require.Len(testing, metricSink, 1, "the result should be sent as soon as the check succeeds")
+	pm := <-metricSink
+	require.Equal(testing, outcomeOnTime, outcomeOf(pm))
+	require.False(testing, pm.findingsReport)
+	subscribedFeedsLock.Lock()
+	subscribed["env1"] = []feeds.Feed{mockFeed("notifications", "", []*feeds.Notification{&n, &n})}
+	subscribedFeedsLock.Unlock()
+
+	select {
+	case report := <-metricSink:
+		require.True(testing, report.findingsReport)
+		require.Equal(testing, []string{findingDuplicateNotification}, report.findings, "the notification delivered again after the check succeeded should be reported")
+	case <-time.After(5 * time.Second):
+		testing.Fatal("no findings at the end of the grace window")
+	}
+}
+
+func TestScheduleCheckDoesNotReportFindingsToTheRechecks(testing *testing.T) {
+	runningChecks = newCheckTracker()
+	n := feeds.Notification{Type: feeds.UpdateNotification, PublishReference: "tid_1", LastModified: time.Now().Format(time.RFC3339Nano)}
+	f := mockFeed("notifications", "", []*feeds.Notification{&n, &n})
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{
+		"content": NotificationsCheck{nil, map[string][]feeds.Feed{"env1": {f}}, "notifications"},
+	}
+	results := make(chan PublishMetric, 2)
+
+	check := newShutdownTestCheck(results)
+	check.Metric.publishDate = time.Now().Add(-time.Second)
+	check.Threshold = 2
+	runningChecks.add()
+	scheduleCheck(check, newMemoryHistory(time.Hour))
+
+	require.Len(testing, results, 1, "only the availability result should be sent to the recheck")
+	require.False(testing, (<-results).findingsReport)
+}
+
+func TestScheduleCheckTracesItsAttemptsInTheSpanOfThePublish(testing *testing.T) {
+	recorder := recordSpans(testing)
+	runningChecks = newCheckTracker()
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": fixedResultCheck{true}}
+	results := make(chan PublishMetric, 1)
+
+	ctx, publishSpan := tracing.Start(context.Background(), "HandleMessage")
+	check := newShutdownTestCheck(results)
+	check.spanContext = trace.SpanContextFromContext(ctx)
+	runningChecks.add()
+	scheduleCheck(check, newMemoryHistory(time.Hour))
+	publishSpan.End()
+
+	spans := recorder.Ended()
+	require.Len(testing, spans, 2)
+	require.Equal(testing, "scheduleCheck", spans[0].Name())
+	require.Equal(testing, publishSpan.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+	require.Equal(testing, publishSpan.SpanContext().SpanID(), spans[0].Parent().SpanID(), "the attempt should be a child of the span of the publish")
+}
+
+func runScheduleChecks(testing *testing.T, content content.Content, mockEnvironments *threadSafeEnvironments) []PublishMetric {
+	capturingMetrics := newMemoryHistory(time.Hour)
 	tid := "tid_1234"
 	publishDate, err := time.Parse(dateLayout, "2016-01-08T14:22:06.271Z")
 	if err != nil {
@@ -186,14 +382,33 @@ func runScheduleChecks(testing *testing.T, content content.Content, mockEnvironm
 	//redefine metricSink to avoid hang
 	metricSink = make(chan PublishMetric, 2)
 
-	scheduleChecks(&schedulerParam{content, publishDate, tid, true, capturingMetrics, mockEnvironments})
+	scheduleChecks(context.Background(), &schedulerParam{
+		contentToCheck:  content,
+		publishDate:     publishDate,
+		tid:             tid,
+		isMarkedDeleted: true,
+		metricContainer: capturingMetrics,
+		environments:    mockEnvironments,
+	})
 	for {
-		capturingMetrics.RLock()
-		if len(capturingMetrics.publishMetrics) == mockEnvironments.len() {
-			return capturingMetrics // with a read lock
+		records, _ := capturingMetrics.Query(HistoryQuery{})
+		if len(records) == mockEnvironments.len() {
+			var publishMetrics []PublishMetric
+			for _, r := range records {
+				publishMetrics = append(publishMetrics, r.metric())
+			}
+			return publishMetrics
 		}
 
-		capturingMetrics.RUnlock()
 		time.Sleep(1 * time.Second)
 	}
 }
+
+// recordSpans records the spans ended until the end of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	saved := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(saved) })
+	return recorder
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	defaultDrainDeadline = 20 * time.Second
+	// how long to wait for the HTTP requests in progress
+	httpShutdownTimeout = 5 * time.Second
+)
+
+var (
+	runningChecks = newCheckTracker()
+	// how long to wait for the aborted checks to record their results
+	abortTimeout = 10 * time.Second
+)
+
+// checkTracker keeps track of the running checks so that they can be drained,
+// or aborted, when the monitor shuts down.
+type checkTracker struct {
+	lock    *sync.Mutex
+	running int
+	waiters []chan struct{}
+	ctx     context.Context
+	abort   context.CancelFunc
+	drop    chan struct{}
+}
+
+func newCheckTracker() *checkTracker {
+	ctx, abort := context.WithCancel(context.Background())
+	return &checkTracker{lock: &sync.Mutex{}, ctx: ctx, abort: abort, drop: make(chan struct{})}
+}
+
+func (t *checkTracker) add() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.running++
+}
+
+func (t *checkTracker) done() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.running--
+	if t.running == 0 {
+		for _, w := range t.waiters {
+			close(w)
+		}
+		t.waiters = nil
+	}
+}
+
+func (t *checkTracker) count() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.running
+}
+
+// idle returns a channel which is closed when no checks are running.
+func (t *checkTracker) idle() <-chan struct{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	w := make(chan struct{})
+	if t.running == 0 {
+		close(w)
+	} else {
+		t.waiters = append(t.waiters, w)
+	}
+	return w
+}
+
+// context returns the context the checks run in, which is cancelled when
+// they are aborted.
+func (t *checkTracker) context() context.Context {
+	return t.ctx
+}
+
+// aborted returns a channel which is closed when the running checks must stop.
+func (t *checkTracker) aborted() <-chan struct{} {
+	return t.ctx.Done()
+}
+
+// send sends the result of a check to sink, unless the results of the
+// checks still running are dropped.
+func (t *checkTracker) send(sink chan<- PublishMetric, pm PublishMetric) {
+	select {
+	case <-t.drop:
+	default:
+		select {
+		case sink <- pm:
+			return
+		case <-t.drop:
+		}
+	}
+	loggerForCheck(pm.config.Alias, pm.UUID, pm.platform, pm.tid).Warn("Dropping the result of the check, as the monitor is shut down")
+}
+
+// drain waits for the running checks to complete until the deadline, then
+// aborts the remaining ones and waits for them to record their results.
+// It returns false if some checks are still running, in which case their
+// results are dropped.
+func (t *checkTracker) drain(deadline time.Duration) bool {
+	select {
+	case <-t.idle():
+		return true
+	case <-time.After(deadline):
+	}
+
+	log.Warnf("Aborting [%d] checks still running after [%v]", t.count(), deadline)
+	t.abort()
+	select {
+	case <-t.idle():
+		return true
+	case <-time.After(abortTimeout):
+		log.Errorf("[%d] aborted checks did not record their results, dropping them", t.count())
+		close(t.drop)
+		return false
+	}
+}
+
+// shutdown stops the monitor once the messages are no longer consumed:
+// it drains the running checks, stops the feeds, waits for the results to
+// reach the metric destinations and flushes them, then closes the HTTP server.
+func shutdown(server *http.Server, aggregator *Aggregator, aggregatorDone <-chan struct{}) {
+	drainDeadline := defaultDrainDeadline
+	if appConfig.DrainDeadline > 0 {
+		drainDeadline = time.Duration(appConfig.DrainDeadline) * time.Second
+	}
+	if syntheticPublishes != nil {
+		syntheticPublishes.Stop()
+	}
+	log.Infof("Shutting down, waiting up to [%v] for [%d] running checks", drainDeadline, runningChecks.count())
+	drained := runningChecks.drain(drainDeadline)
+
+	for envName, envFeeds := range subscribedFeedsSnapshot() {
+		for _, f := range envFeeds {
+			log.Infof("Stopping %v feed for %v", f.FeedName(), envName)
+			f.Stop()
+		}
+	}
+
+	if drained {
+		// the results are sent to metricSink until the checks complete
+		close(metricSink)
+		<-aggregatorDone
+	}
+	aggregator.Flush()
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.WithError(err).Warn("HTTP requests still in progress were interrupted")
+		server.Close()
+	}
+	log.Info("Shutdown complete")
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainWaitsForRunningChecks(t *testing.T) {
+	runningChecks = newCheckTracker()
+	results := make(chan PublishMetric, 1)
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": fixedResultCheck{true}}
+
+	runningChecks.add()
+	go scheduleCheck(newShutdownTestCheck(results), newMemoryHistory(time.Hour))
+	runningChecks.drain(5 * time.Second)
+
+	require.Len(t, results, 1)
+	pm := <-results
+	assert.Equal(t, outcomeOnTime, outcomeOf(pm))
+	assert.Equal(t, 0, runningChecks.count())
+}
+
+func TestDrainAbortsChecksAfterTheDeadline(t *testing.T) {
+	runningChecks = newCheckTracker()
+	results := make(chan PublishMetric, 1)
+	history := newMemoryHistory(time.Hour)
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": fixedResultCheck{false}}
+
+	runningChecks.add()
+	go scheduleCheck(newShutdownTestCheck(results), history)
+	start := time.Now()
+	runningChecks.drain(100 * time.Millisecond)
+
+	assert.True(t, time.Since(start) < 5*time.Second, "the check should not run until its SLA")
+	require.Len(t, results, 1)
+	pm := <-results
+	assert.False(t, pm.publishOK)
+	assert.Equal(t, outcomeAborted, outcomeOf(pm))
+
+	records, _ := history.Query(HistoryQuery{Outcome: outcomeAborted})
+	assert.Len(t, records, 1)
+}
+
+// ctxBoundCheck keeps checking until its context is cancelled.
+type ctxBoundCheck struct{}
+
+func (c ctxBoundCheck) isCurrentOperationFinished(ctx context.Context, pc *PublishCheck) (operationFinished, ignoreCheck bool) {
+	<-ctx.Done()
+	return false, false
+}
+
+// stuckCheck keeps checking until it is released.
+type stuckCheck struct {
+	release chan struct{}
+}
+
+func (c stuckCheck) isCurrentOperationFinished(ctx context.Context, pc *PublishCheck) (operationFinished, ignoreCheck bool) {
+	<-c.release
+	return false, false
+}
+
+func TestDrainCancelsTheContextOfTheAbortedChecks(t *testing.T) {
+	runningChecks = newCheckTracker()
+	results := make(chan PublishMetric, 1)
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": ctxBoundCheck{}}
+
+	runningChecks.add()
+	go scheduleCheck(newShutdownTestCheck(results), newMemoryHistory(time.Hour))
+
+	assert.True(t, runningChecks.drain(100*time.Millisecond))
+	require.Len(t, results, 1)
+	assert.Equal(t, outcomeAborted, outcomeOf(<-results))
+}
+
+func TestDrainDropsTheResultsOfTheChecksWhichDoNotStop(t *testing.T) {
+	defer func(saved time.Duration) { abortTimeout = saved }(abortTimeout)
+	abortTimeout = 100 * time.Millisecond
+	runningChecks = newCheckTracker()
+	results := make(chan PublishMetric)
+	check := stuckCheck{make(chan struct{})}
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": check}
+
+	runningChecks.add()
+	go scheduleCheck(newShutdownTestCheck(results), newMemoryHistory(time.Hour))
+
+	assert.False(t, runningChecks.drain(100*time.Millisecond))
+	close(check.release)
+	select {
+	case <-runningChecks.idle():
+	case <-results:
+		t.Fatal("Expected the result of the check to be dropped")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the check to stop once released")
+	}
+}
+
+func TestDrainReturnsImmediatelyWithoutRunningChecks(t *testing.T) {
+	tracker := newCheckTracker()
+
+	start := time.Now()
+	assert.True(t, tracker.drain(time.Minute))
+
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+type recordingDestination struct {
+	sync.Mutex
+	sent    []PublishMetric
+	flushed bool
+}
+
+func (d *recordingDestination) Send(pm PublishMetric) {
+	time.Sleep(50 * time.Millisecond)
+	d.Lock()
+	defer d.Unlock()
+	d.sent = append(d.sent, pm)
+}
+
+func (d *recordingDestination) Flush() {
+	d.Lock()
+	defer d.Unlock()
+	d.flushed = true
+}
+
+func TestAggregatorRunReturnsOnceAllMetricsAreSent(t *testing.T) {
+	source := make(chan PublishMetric)
+	destination := &recordingDestination{}
+	aggregator := NewAggregator(source, []MetricDestination{destination})
+
+	done := make(chan struct{})
+	go func() {
+		aggregator.Run()
+		close(done)
+	}()
+	source <- PublishMetric{UUID: "uuid1"}
+	source <- PublishMetric{UUID: "uuid2"}
+	close(source)
+	<-done
+
+	destination.Lock()
+	defer destination.Unlock()
+	assert.Len(t, destination.sent, 2)
+}
+
+func TestAggregatorFlushesTheDestinations(t *testing.T) {
+	destination := &recordingDestination{}
+	NewAggregator(make(chan PublishMetric), []MetricDestination{destination}).Flush()
+
+	assert.True(t, destination.flushed)
+}
+
+func newShutdownTestCheck(results chan PublishMetric) PublishCheck {
+	endpoint, _ := url.Parse("http://env1.example.org/content/")
+	pm := PublishMetric{
+		UUID:        "uuid1",
+		publishDate: time.Now(),
+		platform:    "env1",
+		config:      MetricConfig{Alias: "content"},
+		endpoint:    *endpoint,
+		tid:         "tid_1",
+	}
+	return *NewPublishCheck(pm, "", "", 60, 1, results)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 )
 
 // SplunkFeeder implements MetricDestination interface to send PublishMetrics to Splunk.
@@ -21,6 +22,14 @@ func NewSplunkFeeder(logPrefix string) *SplunkFeeder {
 
 // Send logs pm into a file.
 func (sf SplunkFeeder) Send(pm PublishMetric) {
-	sf.MetricLog.Printf("UUID=%v readEnv=%v transaction_id=%v publishDate=%v publishOk=%v duration=%v endpoint=%v ",
-		pm.UUID, pm.platform, pm.tid, pm.publishDate.UnixNano(), pm.publishOK, pm.publishInterval.upperBound, pm.config.Alias)
+	if pm.findingsReport {
+		sf.MetricLog.Printf("UUID=%v readEnv=%v transaction_id=%v publishDate=%v endpoint=%v findings=%v ",
+			pm.UUID, pm.platform, pm.tid, pm.publishDate.UnixNano(), pm.config.Alias, strings.Join(pm.findings, ","))
+		return
+	}
+	sf.MetricLog.Printf("UUID=%v readEnv=%v transaction_id=%v publishDate=%v publishOk=%v duration=%v endpoint=%v outcome=%v threshold=%v latency=%v findings=%v ",
+		pm.UUID, pm.platform, pm.tid, pm.publishDate.UnixNano(), pm.publishOK, pm.publishInterval.upperBound, pm.config.Alias, outcomeOf(pm), pm.threshold, pm.latency.Seconds(), strings.Join(pm.findings, ","))
 }
+
+// Flush does nothing, as the PublishMetrics are logged when they are sent.
+func (sf SplunkFeeder) Flush() {}
@@ -21,5 +21,7 @@ sed -i "s \"METHODE_ARTICLE_INTERNAL_COMPONENTS_MAPPER_URL\" \"$METHODE_ARTICLE_
 sed -i "s \"VIDEO_MAPPER_URL\" \"$VIDEO_MAPPER_URL\" " /config.json
 sed -i "s \"WORDPRESS_MAPPER_URL\" \"$WORDPRESS_MAPPER_URL\" " /config.json
 sed -i "s \"UUID_RESOLVER_URL\" \"$UUID_RESOLVER_URL\" " /config.json
+sed -i "s \"RECHECK_API_KEY\" \"$RECHECK_API_KEY\" " /config.json
+sed -i "s \"ALERT_SLACK_WEBHOOK_URL\" \"$ALERT_SLACK_WEBHOOK_URL\" " /config.json
 
-exec ./publish-availability-monitor -config /config.json -etcd-peers $ETCD_PEERS
+exec ./publish-availability-monitor -config /config.json -etcd-peers $ETCD_PEERS -log-format ${LOG_FORMAT:-text}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// the messages of synthetic publishes are ignored by the message handler, the
+// syntheticPublisher schedules their checks itself
+const syntheticTIDPrefix = "SYNTHETIC-PAM-"
+
+var (
+	syntheticPublishErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pam_synthetic_publish_errors_total",
+		Help: "Number of synthetic publishes which could not be sent to the publish endpoint.",
+	}, []string{"content_type"})
+	syntheticResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pam_synthetic_publish_results_total",
+		Help: "Number of completed synthetic publish checks, by outcome.",
+	}, []string{"environment", "alias", "outcome"})
+	syntheticLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pam_synthetic_publish_latency_seconds",
+		Help:    "Time from the synthetic publish until the content was available, in seconds.",
+		Buckets: []float64{5, 10, 20, 30, 45, 60, 90, 120, 180, 300, 600},
+	}, []string{"environment", "alias"})
+)
+
+// SyntheticConfig holds the configuration of the synthetic publishes
+type SyntheticConfig struct {
+	PublishURL  string            `json:"publishUrl"` //synthetic publishes are disabled when empty
+	Interval    int               `json:"interval"`   //seconds between synthetic publishes, ex. 300
+	UUID        string            `json:"uuid"`
+	ContentType string            `json:"contentType"` //selects the thresholds, the content is checked at every endpoint
+	Payload     json.RawMessage   `json:"payload"`     //the content posted to publishUrl
+	Headers     map[string]string `json:"headers"`     //added to the publish request, ex. { "Origin-System-Id": "..." }
+}
+
+// syntheticRun is the result of one synthetic publish.
+type syntheticRun struct {
+	tid         string
+	publishDate time.Time
+	err         error
+	expected    int
+	results     []PublishMetric
+}
+
+// syntheticPublisher periodically publishes known content and checks it at
+// every configured endpoint in every environment, to prove the checks work
+// when there are no other publishes.
+// The results are kept apart from the real publishes, so they are not part of
+// the SLA measurement.
+type syntheticPublisher struct {
+	conf         SyntheticConfig
+	httpClient   *http.Client
+	environments *threadSafeEnvironments
+	history      PublishHistory
+	lock         *sync.RWMutex
+	lastRun      *syntheticRun
+	stop         chan struct{}
+	stopOnce     *sync.Once
+}
+
+func newSyntheticPublisher(conf SyntheticConfig, httpClient *http.Client, envs *threadSafeEnvironments) *syntheticPublisher {
+	if conf.PublishURL == "" {
+		return nil
+	}
+	return &syntheticPublisher{
+		conf:         conf,
+		httpClient:   httpClient,
+		environments: envs,
+		history:      newMemoryHistory(time.Hour),
+		lock:         &sync.RWMutex{},
+		stop:         make(chan struct{}),
+		stopOnce:     &sync.Once{},
+	}
+}
+
+// Start publishes synthetic content every configured interval, until Stop is called.
+func (s *syntheticPublisher) Start() {
+	interval := time.Duration(s.conf.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	log.Infof("Publishing synthetic content uuid=[%v] to [%v] every [%v]", s.conf.UUID, s.conf.PublishURL, interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.run()
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the synthetic publishes; the checks already running are drained on shutdown.
+func (s *syntheticPublisher) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// run publishes the synthetic content and waits for the results of its checks.
+func (s *syntheticPublisher) run() {
+	publishDate := time.Now()
+	run := &syntheticRun{
+		tid:         fmt.Sprintf("%s%d", syntheticTIDPrefix, publishDate.UnixNano()),
+		publishDate: publishDate,
+	}
+	defer s.setLastRun(run)
+
+	if run.err = s.publish(run.tid); run.err != nil {
+		log.Errorf("Cannot publish synthetic content uuid=[%v] transaction_id=[%v]: [%v]", s.conf.UUID, run.tid, run.err)
+		syntheticPublishErrors.WithLabelValues(s.conf.ContentType).Inc()
+		return
+	}
+
+	results := make(chan PublishMetric, len(appConfig.MetricConf)*(s.environments.len()+1))
+	run.expected = scheduleChecks(context.Background(), &schedulerParam{
+		contentToCheck:  recheckContent{s.conf.UUID, s.conf.ContentType},
+		publishDate:     publishDate,
+		tid:             run.tid,
+		metricContainer: s.history,
+		environments:    s.environments,
+		allEndpoints:    true,
+		resultSink:      results,
+	})
+
+	threshold := appConfig.maxThresholdAtAnyEndpoint(s.conf.ContentType) + appConfig.GraceWindow
+	deadline := time.After(time.Duration(threshold)*time.Second + recheckGracePeriod)
+	for len(run.results) < run.expected {
+		select {
+		case pm := <-results:
+			s.record(pm)
+			run.results = append(run.results, pm)
+		case <-deadline:
+			log.Warnf("Synthetic publish transaction_id=[%v] got [%d] out of [%d] results", run.tid, len(run.results), run.expected)
+			return
+		}
+	}
+}
+
+func (s *syntheticPublisher) publish(tid string) error {
+	req, err := http.NewRequest("POST", s.conf.PublishURL, bytes.NewReader(s.conf.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.conf.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("X-Request-Id", tid)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer cleanupResp(resp)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code [%d]", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *syntheticPublisher) record(pm PublishMetric) {
+	syntheticResults.WithLabelValues(pm.platform, pm.config.Alias, outcomeOf(pm)).Inc()
+	if pm.latency > 0 {
+		syntheticLatency.WithLabelValues(pm.platform, pm.config.Alias).Observe(pm.latency.Seconds())
+	}
+}
+
+func (s *syntheticPublisher) setLastRun(run *syntheticRun) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.lastRun = run
+}
+
+// checkLastRun returns an error unless the last synthetic publish was
+// available on time at every endpoint.
+func (s *syntheticPublisher) checkLastRun() (string, error) {
+	s.lock.RLock()
+	run := s.lastRun
+	s.lock.RUnlock()
+
+	if run == nil {
+		return "No synthetic publish completed yet", nil
+	}
+	if run.err != nil {
+		return "", fmt.Errorf("synthetic publish transaction_id=[%s] failed: %v", run.tid, run.err)
+	}
+
+	var failures []string
+	for _, pm := range run.results {
+		if outcome := outcomeOf(pm); outcome != outcomeOnTime {
+			failures = append(failures, fmt.Sprintf("%s/%s: %s", pm.platform, pm.config.Alias, outcome))
+		}
+	}
+	if missing := run.expected - len(run.results); missing > 0 {
+		failures = append(failures, fmt.Sprintf("%d checks without result", missing))
+	}
+	if len(failures) > 0 {
+		return "", fmt.Errorf("synthetic publish transaction_id=[%s] was not available on time: %s", run.tid, strings.Join(failures, ", "))
+	}
+	return fmt.Sprintf("Synthetic publish transaction_id=[%s] was available on time at %d endpoints", run.tid, len(run.results)), nil
+}
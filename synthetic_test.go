@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyntheticPublishIsCheckedAtEveryEndpoint(t *testing.T) {
+	var publishedTID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		publishedTID = r.Header.Get("X-Request-Id")
+		assert.Equal(t, "methode-web-pub", r.Header.Get("Origin-System-Id"))
+	}))
+	defer server.Close()
+	synthetic := newSyntheticTestPublisher(server.URL)
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{
+		"content": fixedResultCheck{true},
+		"lists":   fixedResultCheck{true},
+	}
+
+	synthetic.run()
+
+	assert.True(t, strings.HasPrefix(publishedTID, syntheticTIDPrefix))
+	assert.True(t, (&kafkaMessageHandler{}).isIgnorableMessage(publishedTID), "the message of the synthetic publish should not be checked twice")
+	records, _ := synthetic.history.Query(HistoryQuery{TransactionID: publishedTID})
+	assert.Len(t, records, 4, "the content should be checked at every endpoint in every environment, whatever their content types")
+	msg, err := synthetic.checkLastRun()
+	assert.NoError(t, err)
+	assert.Contains(t, msg, "available on time at 4 endpoints")
+}
+
+func TestSyntheticPublishHealthcheckFailsWhenContentIsMissing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	synthetic := newSyntheticTestPublisher(server.URL)
+	appConfig.GraceWindow = 1
+	endpointSpecificChecks = map[string]EndpointSpecificCheck{"content": fixedResultCheck{false}}
+
+	synthetic.run()
+
+	_, err := synthetic.checkLastRun()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "env1/content: missing")
+}
+
+func TestSyntheticPublishHealthcheckFailsWhenThePublishFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	synthetic := newSyntheticTestPublisher(server.URL)
+
+	_, err := synthetic.checkLastRun()
+	assert.NoError(t, err, "no synthetic publish should not be reported as a failure")
+
+	synthetic.run()
+
+	_, err = synthetic.checkLastRun()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status code [503]")
+}
+
+func TestNoSyntheticPublisherWithoutPublishURL(t *testing.T) {
+	assert.Nil(t, newSyntheticPublisher(SyntheticConfig{}, http.DefaultClient, newThreadSafeEnvironments()))
+}
+
+func newSyntheticTestPublisher(publishURL string) *syntheticPublisher {
+	appConfig = &AppConfig{
+		MetricConf: []MetricConfig{
+			{Endpoint: "/content/", Granularity: 1, Alias: "content", ContentTypes: []string{"EOM::CompoundStory"}},
+			{Endpoint: "/lists/", Granularity: 1, Alias: "lists", ContentTypes: []string{"EOM::WebContainer"}},
+		},
+		Threshold: 1,
+	}
+	runningChecks = newCheckTracker()
+
+	envs := newThreadSafeEnvironments()
+	envs.envMap["env1"] = Environment{"env1", "http://env1.example.org", "", "", ""}
+	envs.envMap["env2"] = Environment{"env2", "http://env2.example.org", "", "", ""}
+	conf := SyntheticConfig{
+		PublishURL:  publishURL,
+		UUID:        "uuid1",
+		ContentType: "EOM::CompoundStory",
+		Payload:     []byte(`{"uuid": "uuid1"}`),
+		Headers:     map[string]string{"Origin-System-Id": "methode-web-pub"},
+	}
+	return newSyntheticPublisher(conf, http.DefaultClient, envs)
+}
@@ -0,0 +1,94 @@
+// Package tracing records spans of the work done for each publish, and exports
+// them to an OpenTelemetry collector with OTLP.
+//
+// The spans are parented through the context passed down the calls, and the
+// trace is propagated to and from the other services with the W3C Trace Context
+// traceparent header.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	serviceName = "publish-availability-monitor"
+	tracerName  = "github.com/Financial-Times/publish-availability-monitor/tracing"
+)
+
+// TraceParentHeader propagates the trace to the downstream services, in the W3C Trace Context format.
+const TraceParentHeader = "traceparent"
+
+func init() {
+	// the trace is propagated even when the monitor does not export its own spans
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+// Enable exports the spans to the OTLP/HTTP collector at endpoint. The traces
+// started by the monitor are sampled with samplingRatio, the others follow the
+// sampling decision of their parent. The returned function flushes the spans
+// and stops exporting them.
+func Enable(endpoint string, samplingRatio float64) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span of ctx, and returns the context of the operations done during the span.
+func Start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name)
+}
+
+// StartClient starts a span of ctx for a call to another service.
+func StartClient(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+}
+
+// SetError records that the operation of span failed.
+func SetError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Inject sets the TraceParentHeader of a call made during the span of ctx.
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Extract returns ctx continuing the trace of the message with headers, if any.
+func Extract(ctx context.Context, headers map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// TraceParent returns the value of the TraceParentHeader of sc, or the empty
+// string when sc is not valid, so that the trace can be resumed later with ParseTraceParent.
+func TraceParent(sc trace.SpanContext) string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(trace.ContextWithSpanContext(context.Background(), sc), carrier)
+	return carrier.Get(TraceParentHeader)
+}
+
+// ParseTraceParent returns the span context of traceParent, which is not valid
+// when traceParent is empty or malformed.
+func ParseTraceParent(traceParent string) trace.SpanContext {
+	ctx := Extract(context.Background(), map[string]string{TraceParentHeader: traceParent})
+	return trace.SpanContextFromContext(ctx)
+}
@@ -0,0 +1,120 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpansAreParentedThroughTheContext(t *testing.T) {
+	recorder := recordSpans(t)
+
+	ctx, root := Start(context.Background(), "HandleMessage")
+	preCheckCtx, preCheck := Start(ctx, "mainPreCheck")
+	_, call := StartClient(preCheckCtx, "HTTP POST")
+	call.End()
+	preCheck.End()
+	root.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "HTTP POST", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID(), "the call should be a child of the pre-check")
+	assert.Equal(t, spans[2].SpanContext().SpanID(), spans[1].Parent().SpanID(), "the pre-check should be a child of the root span")
+	assert.False(t, spans[2].Parent().IsValid())
+	for _, s := range spans {
+		assert.Equal(t, spans[2].SpanContext().TraceID(), s.SpanContext().TraceID())
+	}
+}
+
+func TestSetError(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, span := Start(context.Background(), "HTTP GET")
+	SetError(span, errors.New("connection refused"))
+	span.End()
+
+	require.Len(t, recorder.Ended(), 1)
+	assert.Equal(t, codes.Error, recorder.Ended()[0].Status().Code)
+	assert.Equal(t, "connection refused", recorder.Ended()[0].Status().Description)
+}
+
+func TestInjectSetsTheTraceParentOfTheSpan(t *testing.T) {
+	recordSpans(t)
+
+	ctx, span := StartClient(context.Background(), "HTTP GET")
+	defer span.End()
+	header := http.Header{}
+	Inject(ctx, header)
+
+	traceParent := header.Get(TraceParentHeader)
+	assert.True(t, regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`).MatchString(traceParent), traceParent)
+	assert.Equal(t, "00-"+span.SpanContext().TraceID().String()+"-"+span.SpanContext().SpanID().String()+"-01", traceParent)
+}
+
+func TestExtractContinuesTheTraceOfAMessage(t *testing.T) {
+	recorder := recordSpans(t)
+
+	ctx := Extract(context.Background(), map[string]string{TraceParentHeader: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"})
+	_, span := Start(ctx, "HandleMessage")
+	span.End()
+
+	require.Len(t, recorder.Ended(), 1)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", recorder.Ended()[0].SpanContext().TraceID().String())
+	assert.Equal(t, "b7ad6b7169203331", recorder.Ended()[0].Parent().SpanID().String())
+}
+
+func TestTraceParentRoundTrip(t *testing.T) {
+	recordSpans(t)
+
+	_, span := Start(context.Background(), "HandleMessage")
+	span.End()
+
+	traceParent := TraceParent(span.SpanContext())
+	assert.NotEmpty(t, traceParent)
+	sc := ParseTraceParent(traceParent)
+	assert.Equal(t, span.SpanContext().TraceID(), sc.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), sc.SpanID())
+
+	assert.Empty(t, TraceParent(trace.SpanContext{}))
+	assert.False(t, ParseTraceParent("").IsValid())
+	assert.False(t, ParseTraceParent("not a traceparent").IsValid())
+}
+
+func TestUnsampledTracesAreNotRecorded(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	setProvider(t, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder), sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0)))))
+
+	ctx, root := Start(context.Background(), "HandleMessage")
+	_, child := Start(ctx, "mainPreCheck")
+	child.End()
+	root.End()
+
+	assert.Empty(t, recorder.Ended())
+	header := http.Header{}
+	Inject(ctx, header)
+	assert.Regexp(t, "-00$", header.Get(TraceParentHeader), "the downstream services should be told that the trace is not sampled")
+}
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	setProvider(t, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+func setProvider(t *testing.T, provider trace.TracerProvider) {
+	saved := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(saved) })
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Financial-Times/message-queue-gonsumer/consumer"
+	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/Financial-Times/publish-availability-monitor/jsonpath"
+)
+
+// TypeRule routes the content of the messages it matches to the endpoints
+// whose contentTypes include its Type. The conditions which are set must all
+// match. They are globs, where * matches any characters, or regular
+// expressions between slashes, ex. "/^EOM::(Story|CompoundStory)$/".
+type TypeRule struct {
+	SystemID    string            `json:"systemId,omitempty"`    //the Origin-System-Id header, ex. "http://cmdb.ft.com/systems/methode-web-pub"
+	ContentType string            `json:"contentType,omitempty"` //the type of the content, ex. "EOM::CompoundStory*"
+	SourceCode  string            `json:"sourceCode,omitempty"`  //the source code of Methode content, ex. "FT"
+	Category    string            `json:"category,omitempty"`    //the wires category of Methode content, ex. "blog"
+	Headers     map[string]string `json:"headers,omitempty"`     //other headers of the message, by name
+	Fields      map[string]string `json:"fields,omitempty"`      //the values at JSON paths of the message, ex. { "$.post.type": "post" }
+	Type        string            `json:"type"`                  //the type the content is monitored as
+	// UUIDResolved only matches the content placeholders whose UUID was resolved
+	// to the one of the content they hold. It is only set in the methodeTypeRules.
+	UUIDResolved bool `json:"-"`
+}
+
+// typeRouter derives the type content is monitored as from the first TypeRule it matches.
+type typeRouter struct {
+	rules []typeRoute
+}
+
+type typeRoute struct {
+	monitoringType string
+	systemID       *regexp.Regexp
+	contentType    *regexp.Regexp
+	sourceCode     *regexp.Regexp
+	category       *regexp.Regexp
+	headers        map[string]*regexp.Regexp
+	fields         []fieldPattern
+	uuidResolved   bool
+}
+
+type fieldPattern struct {
+	path    jsonpath.Path
+	pattern *regexp.Regexp
+}
+
+// messageFacts are what type rules match, extracted from a message at most once.
+type messageFacts struct {
+	msg          consumer.Message
+	content      content.Content
+	attributes   *content.Attributes
+	body         interface{}
+	bodyParsed   bool
+	contentType  string
+	uuidResolved bool
+}
+
+// contentTypeRouter is nil when no type rule is configured.
+var contentTypeRouter *typeRouter
+
+func newTypeRouter(rules []TypeRule) (*typeRouter, error) {
+	router := &typeRouter{}
+	for i, rule := range rules {
+		if rule.Type == "" {
+			return nil, fmt.Errorf("type rule %d has no type", i)
+		}
+		route := typeRoute{monitoringType: rule.Type, headers: make(map[string]*regexp.Regexp), uuidResolved: rule.UUIDResolved}
+		var err error
+		for _, p := range []struct {
+			pattern string
+			regexp  **regexp.Regexp
+		}{
+			{rule.SystemID, &route.systemID},
+			{rule.ContentType, &route.contentType},
+			{rule.SourceCode, &route.sourceCode},
+			{rule.Category, &route.category},
+		} {
+			if *p.regexp, err = compilePattern(p.pattern); err != nil {
+				return nil, fmt.Errorf("type rule %d: %v", i, err)
+			}
+		}
+		for name, pattern := range rule.Headers {
+			if route.headers[name], err = compilePattern(pattern); err != nil {
+				return nil, fmt.Errorf("type rule %d: %v", i, err)
+			}
+		}
+		for path, pattern := range rule.Fields {
+			field := fieldPattern{}
+			if field.path, err = jsonpath.Parse(path); err != nil {
+				return nil, fmt.Errorf("type rule %d: %v", i, err)
+			}
+			if field.pattern, err = compilePattern(pattern); err != nil {
+				return nil, fmt.Errorf("type rule %d: %v", i, err)
+			}
+			route.fields = append(route.fields, field)
+		}
+		router.rules = append(router.rules, route)
+	}
+	return router, nil
+}
+
+// mustNewTypeRouter is like newTypeRouter but panics if the rules are invalid.
+// It is used for the rules defined in the code.
+func mustNewTypeRouter(rules []TypeRule) *typeRouter {
+	router, err := newTypeRouter(rules)
+	if err != nil {
+		panic(err)
+	}
+	return router
+}
+
+// compilePattern compiles a glob, or a regular expression between slashes.
+// The empty pattern, which matches anything, is compiled as nil.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return regexp.Compile(pattern[1 : len(pattern)-1])
+	}
+	glob := regexp.QuoteMeta(pattern)
+	return regexp.Compile("^" + strings.Replace(glob, `\*`, ".*", -1) + "$")
+}
+
+// monitoringType returns the type c, published by msg, is monitored as.
+func (r *typeRouter) monitoringType(c content.Content, msg consumer.Message) string {
+	return r.route(&messageFacts{msg: msg, content: c, contentType: c.GetType()})
+}
+
+// route returns the type of the first rule matching facts, or the type of the
+// content if none does.
+func (r *typeRouter) route(facts *messageFacts) string {
+	for _, route := range r.rules {
+		if route.matches(facts) {
+			return route.monitoringType
+		}
+	}
+	return facts.contentType
+}
+
+func (route typeRoute) matches(facts *messageFacts) bool {
+	if !matchesPattern(route.systemID, facts.msg.Headers[systemIDKey]) ||
+		!matchesPattern(route.contentType, facts.contentType) ||
+		(route.uuidResolved && !facts.uuidResolved) {
+		return false
+	}
+	if route.sourceCode != nil || route.category != nil {
+		attributes := facts.methodeAttributes()
+		if attributes == nil || !matchesPattern(route.sourceCode, attributes.SourceCode) || !matchesPattern(route.category, attributes.Category) {
+			return false
+		}
+	}
+	for name, pattern := range route.headers {
+		if !pattern.MatchString(facts.msg.Headers[name]) {
+			return false
+		}
+	}
+	for _, field := range route.fields {
+		if !field.path.Matches(facts.parsedBody(), field.pattern.MatchString) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesPattern(pattern *regexp.Regexp, value string) bool {
+	return pattern == nil || pattern.MatchString(value)
+}
+
+// methodeAttributes returns the attributes of Methode content, or nil for other content.
+func (f *messageFacts) methodeAttributes() *content.Attributes {
+	if f.attributes == nil {
+		eomFile, ok := f.content.(content.EomFile)
+		if !ok {
+			return nil
+		}
+		f.attributes = &content.Attributes{}
+		xml.Unmarshal([]byte(eomFile.Attributes), f.attributes)
+		if eomFile.Source.SourceCode != "" {
+			// the source code was parsed when the message was unmarshalled
+			f.attributes.SourceCode = eomFile.Source.SourceCode
+		}
+	}
+	return f.attributes
+}
+
+func (f *messageFacts) parsedBody() interface{} {
+	if !f.bodyParsed {
+		json.Unmarshal([]byte(f.msg.Body), &f.body)
+		f.bodyParsed = true
+	}
+	return f.body
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Financial-Times/message-queue-gonsumer/consumer"
+	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const methodeSystemID = "http://cmdb.ft.com/systems/methode-web-pub"
+
+func TestTypeRulesMatchMethodeAttributes(t *testing.T) {
+	router, err := newTypeRouter([]TypeRule{
+		{SystemID: methodeSystemID, ContentType: "EOM::CompoundStory*", Category: "/^(blog|fastft)$/", Type: "BlogPost"},
+		{SystemID: methodeSystemID, SourceCode: "FT", Type: "FTStory"},
+	})
+	require.NoError(t, err)
+	msg := consumer.Message{Headers: map[string]string{"Origin-System-Id": methodeSystemID}}
+	attributes := `<ObjectMetadata><EditorialNotes><Sources><Source><SourceCode>%s</SourceCode></Source></Sources></EditorialNotes><WiresIndexing><category>%s</category></WiresIndexing></ObjectMetadata>`
+
+	blogPost := content.EomFile{Type: "EOM::CompoundStory_Internal_CPH", Attributes: fmt.Sprintf(attributes, "ContentPlaceholder", "fastft")}
+	assert.Equal(t, "BlogPost", router.monitoringType(blogPost, msg))
+
+	story := content.EomFile{Type: "EOM::CompoundStory", Attributes: fmt.Sprintf(attributes, "FT", "")}
+	assert.Equal(t, "FTStory", router.monitoringType(story, msg))
+
+	wire := content.EomFile{Type: "EOM::CompoundStory", Attributes: fmt.Sprintf(attributes, "Reuters", "")}
+	assert.Equal(t, "EOM::CompoundStory", router.monitoringType(wire, msg), "content matching no rule should keep its type")
+}
+
+func TestTypeRulesMatchHeadersAndFields(t *testing.T) {
+	router, err := newTypeRouter([]TypeRule{
+		{Headers: map[string]string{"Content-Type": "application/vnd.ft-upp-live-blog*"}, Type: "LiveBlog"},
+		{Fields: map[string]string{"$.post.type": "/^(post|page)$/", "$.post.tags[*]": "podcast"}, Type: "Podcast"},
+	})
+	require.NoError(t, err)
+	wordPressPost := content.WordPressMessage{}
+
+	liveBlog := consumer.Message{Headers: map[string]string{"Content-Type": "application/vnd.ft-upp-live-blog+json"}, Body: `{}`}
+	assert.Equal(t, "LiveBlog", router.monitoringType(wordPressPost, liveBlog))
+
+	podcast := consumer.Message{Body: `{"post": {"type": "post", "tags": ["markets", "podcast"]}}`}
+	assert.Equal(t, "Podcast", router.monitoringType(wordPressPost, podcast))
+
+	post := consumer.Message{Body: `{"post": {"type": "post", "tags": ["markets"]}}`}
+	assert.Equal(t, "wordpress", router.monitoringType(wordPressPost, post), "all the conditions of a rule should match")
+}
+
+func TestInvalidTypeRules(t *testing.T) {
+	_, err := newTypeRouter([]TypeRule{{ContentType: "EOM::Story"}})
+	assert.Error(t, err, "rules should have a type")
+	_, err = newTypeRouter([]TypeRule{{ContentType: "/EOM::(Story/", Type: "Story"}})
+	assert.Error(t, err)
+	_, err = newTypeRouter([]TypeRule{{Fields: map[string]string{"$.tags[first]": "podcast"}, Type: "Podcast"}})
+	assert.Error(t, err)
+}
+
+func TestCompilePattern(t *testing.T) {
+	glob, err := compilePattern("http://cmdb.ft.com/systems/*")
+	require.NoError(t, err)
+	assert.True(t, glob.MatchString("http://cmdb.ft.com/systems/wordpress"))
+	assert.False(t, glob.MatchString("https://cmdb.ft.com/systems/wordpress"), "globs should match the whole value")
+
+	re, err := compilePattern("/Story$/")
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("EOM::CompoundStory"))
+
+	empty, err := compilePattern("")
+	assert.NoError(t, err)
+	assert.Nil(t, empty)
+}
+
+func TestScheduleChecksSelectsEndpointsByTheMonitoringType(t *testing.T) {
+	p := &schedulerParam{contentToCheck: content.Video{ID: "1234-1234"}}
+	assert.Equal(t, "video", p.monitoringType())
+
+	p.contentType = "ShortVideo"
+	assert.Equal(t, "ShortVideo", p.monitoringType())
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	defaultAlertGroupWindow    = 30 * time.Second
+	defaultAlertSuppressWindow = time.Hour
+)
+
+// AlertConfig holds the configuration of the webhooks alerted about failed publishes
+type AlertConfig struct {
+	Webhooks       []WebhookConfig `json:"webhooks"`
+	GroupWindow    int             `json:"groupWindow"`    //seconds to wait for other failures of the same UUID before alerting, ex. 30
+	SuppressWindow int             `json:"suppressWindow"` //seconds during which a UUID is not alerted about again, ex. 3600
+}
+
+// WebhookConfig is a webhook alerted about failed publishes
+type WebhookConfig struct {
+	URL    string `json:"url"`
+	Format string `json:"format"` //"json" (default) or "slack"
+}
+
+// alert groups the failures of a publish across environments and endpoints.
+type alert struct {
+	UUID          string         `json:"uuid"`
+	TransactionID string         `json:"transactionId"`
+	ContentType   string         `json:"contentType,omitempty"`
+	PublishDate   time.Time      `json:"publishDate"`
+	Failures      []alertFailure `json:"failures"`
+	firstSeen     time.Time
+}
+
+type alertFailure struct {
+	Environment string  `json:"environment"`
+	Alias       string  `json:"alias"`
+	Endpoint    string  `json:"endpoint"`
+	Outcome     string  `json:"outcome"`
+	Latency     float64 `json:"latency,omitempty"`
+}
+
+type alertPayload struct {
+	Alerts []alert `json:"alerts"`
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// WebhookFeeder implements MetricDestination interface to post the publishes
+// which breached their SLA to webhooks.
+// The failures of a UUID are grouped into one alert, the UUIDs alerted about
+// are not alerted about again within the suppress window, and the webhooks
+// are posted to at most once per group window.
+type WebhookFeeder struct {
+	sync.Mutex
+	webhooks       []WebhookConfig
+	httpClient     *http.Client
+	groupWindow    time.Duration
+	suppressWindow time.Duration
+	pending        map[string]*alert
+	lastAlerted    map[string]time.Time
+	limiter        *EventLimiter
+}
+
+// NewWebhookFeeder returns a WebhookFeeder posting to the webhooks in conf,
+// or nil if none is configured.
+func NewWebhookFeeder(conf AlertConfig, httpClient *http.Client) *WebhookFeeder {
+	var webhooks []WebhookConfig
+	for _, w := range conf.Webhooks {
+		if w.URL != "" {
+			webhooks = append(webhooks, w)
+		}
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	wf := &WebhookFeeder{
+		webhooks:       webhooks,
+		httpClient:     httpClient,
+		groupWindow:    defaultAlertGroupWindow,
+		suppressWindow: defaultAlertSuppressWindow,
+		pending:        make(map[string]*alert),
+		lastAlerted:    make(map[string]time.Time),
+	}
+	if conf.GroupWindow > 0 {
+		wf.groupWindow = time.Duration(conf.GroupWindow) * time.Second
+	}
+	if conf.SuppressWindow > 0 {
+		wf.suppressWindow = time.Duration(conf.SuppressWindow) * time.Second
+	}
+	wf.limiter = NewEventLimiterWithInterval(wf.groupWindow, wf.flush)
+	return wf
+}
+
+// Send adds pm to the alert of its UUID if it breached its SLA.
+func (wf *WebhookFeeder) Send(pm PublishMetric) {
+	outcome := outcomeOf(pm)
+	if pm.findingsReport || !breachesSLA(outcome) {
+		return
+	}
+
+	wf.Lock()
+	defer wf.Unlock()
+	if lastAlerted, found := wf.lastAlerted[pm.UUID]; found && time.Since(lastAlerted) < wf.suppressWindow {
+		log.Infof("Not alerting again about UUID [%v] for environment [%v] and endpoint [%v]", pm.UUID, pm.platform, pm.config.Alias)
+		return
+	}
+
+	a, found := wf.pending[pm.UUID]
+	if !found {
+		a = &alert{
+			UUID:          pm.UUID,
+			TransactionID: pm.tid,
+			ContentType:   pm.contentType,
+			PublishDate:   pm.publishDate,
+			firstSeen:     time.Now(),
+		}
+		wf.pending[pm.UUID] = a
+	}
+	a.Failures = append(a.Failures, alertFailure{
+		Environment: pm.platform,
+		Alias:       pm.config.Alias,
+		Endpoint:    pm.endpoint.String(),
+		Outcome:     outcome,
+		Latency:     pm.latency.Seconds(),
+	})
+	wf.limiter.trigger <- true
+}
+
+// flush posts the alerts which waited for the group window to the webhooks.
+func (wf *WebhookFeeder) flush() {
+	wf.postAlerts(wf.takeReadyAlerts(time.Now(), wf.groupWindow))
+}
+
+// Flush posts all the pending alerts to the webhooks, without waiting for
+// the end of their group window.
+func (wf *WebhookFeeder) Flush() {
+	wf.postAlerts(wf.takeReadyAlerts(time.Now(), 0))
+}
+
+func (wf *WebhookFeeder) postAlerts(alerts []alert) {
+	if len(alerts) == 0 {
+		return
+	}
+
+	for _, w := range wf.webhooks {
+		if err := wf.post(w, alerts); err != nil {
+			log.Errorf("Cannot alert webhook [%v] about [%d] failed publishes: [%v]", w.URL, len(alerts), err)
+		}
+	}
+}
+
+// takeReadyAlerts removes the alerts which waited for at least window from
+// the pending ones, and returns them.
+func (wf *WebhookFeeder) takeReadyAlerts(now time.Time, window time.Duration) []alert {
+	wf.Lock()
+	defer wf.Unlock()
+
+	var ready []alert
+	for uuid, a := range wf.pending {
+		if now.Sub(a.firstSeen) < window {
+			continue
+		}
+		ready = append(ready, *a)
+		wf.lastAlerted[uuid] = now
+		delete(wf.pending, uuid)
+	}
+	if len(wf.pending) > 0 {
+		// the other alerts are posted with the next batch
+		wf.limiter.trigger <- true
+	}
+
+	for uuid, lastAlerted := range wf.lastAlerted {
+		if now.Sub(lastAlerted) >= wf.suppressWindow {
+			delete(wf.lastAlerted, uuid)
+		}
+	}
+
+	sort.Slice(ready, func(i, j int) bool { return ready[i].firstSeen.Before(ready[j].firstSeen) })
+	return ready
+}
+
+func (wf *WebhookFeeder) post(w WebhookConfig, alerts []alert) error {
+	var payload interface{} = alertPayload{alerts}
+	if w.Format == "slack" {
+		payload = slackPayload{slackText(alerts)}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := wf.httpClient.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer cleanupResp(resp)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code [%d]", resp.StatusCode)
+	}
+	return nil
+}
+
+func slackText(alerts []alert) string {
+	lines := []string{fmt.Sprintf("*%d publishes breached their SLA*", len(alerts))}
+	for _, a := range alerts {
+		var failures []string
+		for _, f := range a.Failures {
+			failure := fmt.Sprintf("%s in %s/%s", f.Outcome, f.Environment, f.Alias)
+			if f.Latency > 0 {
+				failure += fmt.Sprintf(" after %.0fs", f.Latency)
+			}
+			failures = append(failures, failure)
+		}
+		lines = append(lines, fmt.Sprintf("• `%s` (%s, transaction_id=%s): %s", a.UUID, a.ContentType, a.TransactionID, strings.Join(failures, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
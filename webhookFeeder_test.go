@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookFeederGroupsFailuresOfTheSameUUID(t *testing.T) {
+	server, requests := newWebhookTestServer()
+	defer server.Close()
+	feeder := newWebhookTestFeeder(WebhookConfig{URL: server.URL})
+
+	failure1 := newHistoryTestMetric("uuid1", "tid_1", "env1", "content", time.Now(), false)
+	failure2 := newHistoryTestMetric("uuid1", "tid_1", "env2", "S3", time.Now(), false)
+	failure2.outcome = outcomeLate
+	failure2.latency = 150 * time.Second
+	feeder.Send(failure1)
+	feeder.Send(failure2)
+	feeder.Send(newHistoryTestMetric("uuid2", "tid_2", "env1", "content", time.Now(), true))
+	aborted := newHistoryTestMetric("uuid3", "tid_3", "env1", "content", time.Now(), false)
+	aborted.outcome = outcomeAborted
+	feeder.Send(aborted)
+
+	var payload alertPayload
+	require.NoError(t, json.Unmarshal(receiveWebhookRequest(t, requests), &payload))
+	require.Len(t, payload.Alerts, 1, "only the publishes breaching their SLA should be alerted about")
+	assert.Equal(t, "uuid1", payload.Alerts[0].UUID)
+	require.Len(t, payload.Alerts[0].Failures, 2)
+	assert.Equal(t, outcomeMissing, payload.Alerts[0].Failures[0].Outcome)
+	assert.Equal(t, outcomeLate, payload.Alerts[0].Failures[1].Outcome)
+	assert.Equal(t, 150.0, payload.Alerts[0].Failures[1].Latency)
+}
+
+func TestWebhookFeederSuppressesRepeatedAlerts(t *testing.T) {
+	server, requests := newWebhookTestServer()
+	defer server.Close()
+	feeder := newWebhookTestFeeder(WebhookConfig{URL: server.URL})
+
+	feeder.Send(newHistoryTestMetric("uuid1", "tid_1", "env1", "content", time.Now(), false))
+	receiveWebhookRequest(t, requests)
+
+	feeder.Send(newHistoryTestMetric("uuid1", "tid_2", "env1", "content", time.Now(), false))
+	select {
+	case <-requests:
+		t.Error("Expected the second alert about the same UUID to be suppressed")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestWebhookFeederPostsSlackMessages(t *testing.T) {
+	server, requests := newWebhookTestServer()
+	defer server.Close()
+	feeder := newWebhookTestFeeder(WebhookConfig{URL: server.URL, Format: "slack"})
+
+	feeder.Send(newHistoryTestMetric("uuid1", "tid_1", "env1", "content", time.Now(), false))
+
+	var payload slackPayload
+	require.NoError(t, json.Unmarshal(receiveWebhookRequest(t, requests), &payload))
+	assert.Contains(t, payload.Text, "`uuid1`")
+	assert.Contains(t, payload.Text, "missing in env1/content")
+}
+
+func TestNoWebhookFeederWithoutWebhooks(t *testing.T) {
+	assert.Nil(t, NewWebhookFeeder(AlertConfig{Webhooks: []WebhookConfig{{URL: ""}}}, http.DefaultClient))
+}
+
+func TestWebhookFeederFlushPostsThePendingAlerts(t *testing.T) {
+	server, requests := newWebhookTestServer()
+	defer server.Close()
+	feeder := NewWebhookFeeder(AlertConfig{Webhooks: []WebhookConfig{{URL: server.URL}}}, http.DefaultClient)
+
+	feeder.Send(newHistoryTestMetric("uuid1", "tid_1", "env1", "content", time.Now(), false))
+	feeder.Flush()
+
+	select {
+	case body := <-requests:
+		var payload alertPayload
+		require.NoError(t, json.Unmarshal(body, &payload))
+		require.Len(t, payload.Alerts, 1)
+		assert.Equal(t, "uuid1", payload.Alerts[0].UUID)
+	default:
+		t.Fatal("Expected the alert to be posted without waiting for the group window")
+	}
+}
+
+func newWebhookTestFeeder(webhook WebhookConfig) *WebhookFeeder {
+	feeder := NewWebhookFeeder(AlertConfig{Webhooks: []WebhookConfig{webhook}}, http.DefaultClient)
+	feeder.groupWindow = 50 * time.Millisecond
+	feeder.limiter = NewEventLimiterWithInterval(feeder.groupWindow, feeder.flush)
+	return feeder
+}
+
+func newWebhookTestServer() (*httptest.Server, chan []byte) {
+	requests := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		json.NewDecoder(r.Body).Decode(&body)
+		requests <- body
+	}))
+	return server, requests
+}
+
+func receiveWebhookRequest(t *testing.T, requests chan []byte) []byte {
+	select {
+	case body := <-requests:
+		return body
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a webhook request")
+		return nil
+	}
+}